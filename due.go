@@ -0,0 +1,112 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/widget"
+	"oddstream.cj/tasks"
+	"oddstream.cj/util"
+)
+
+const (
+	maxDueShown = 5  // the most tasks listed in the banner above a note
+	dueWidth    = 60 // width of a task in the banner, in runes, as buttons don't truncate their text
+)
+
+// today returns the start of today
+func today() time.Time {
+	return util.DayOf(time.Now())
+}
+
+// dueRows lists the open tasks with due dates under Overdue, Today and Upcoming headings, for the task panel
+func dueRows(day time.Time) []taskRow {
+	var rows []taskRow
+	add := func(heading string, due []tasks.Task) {
+		if len(due) == 0 {
+			return
+		}
+		rows = append(rows, taskRow{heading: heading})
+		for _, t := range due {
+			rows = append(rows, taskRow{task: t})
+		}
+	}
+	add("Overdue", theTaskCatalogue.Due(time.Time{}, day.AddDate(0, 0, -1)))
+	add("Today", theTaskCatalogue.Due(day, day))
+	add("Upcoming", theTaskCatalogue.Due(day.AddDate(0, 0, 1), time.Time{}))
+	return rows
+}
+
+// buildDueBanner makes the banner above the note listing the tasks due that day, hidden until there are some
+func (u *ui) buildDueBanner() *fyne.Container {
+	u.dueBanner = container.New(layout.NewVBoxLayout())
+	u.dueBanner.Hide()
+	return u.dueBanner
+}
+
+// showDue lists the tasks from other notes that are due on the day of the current note
+// in the banner above it; the note for today also lists the tasks that are overdue
+func (u *ui) showDue() {
+	u.dueBanner.Objects = nil
+	if theNote.Date.Year() == 1 || theTaskCatalogue == nil {
+		u.dueBanner.Hide()
+		return
+	}
+	day := util.DayOf(theNote.Date)
+	from := day
+	if day.Equal(today()) {
+		from = time.Time{}
+	}
+	var due []tasks.Task
+	for _, t := range theTaskCatalogue.Due(from, day) {
+		if t.Pathname != theNote.Pathname {
+			due = append(due, t)
+		}
+	}
+	if len(due) == 0 {
+		u.dueBanner.Hide()
+		return
+	}
+	for i, t := range due {
+		if i == maxDueShown {
+			more := widget.NewButton(fmt.Sprintf("and %d more", len(due)-maxDueShown), func() {
+				u.taskFilter.SetSelected(tasksDue)
+				u.sideTabs.SelectIndex(2)
+			})
+			more.Importance = widget.LowImportance
+			u.dueBanner.Add(more)
+			break
+		}
+		t := t
+		text := "Due: " + t.Text
+		if t.Due.Before(day) {
+			text = fmt.Sprintf("Overdue since %s: %s", t.Due.Format("Mon 2 Jan 2006"), t.Text)
+		}
+		if r := []rune(text); len(r) > dueWidth {
+			text = string(r[:dueWidth-1]) + "…"
+		}
+		b := widget.NewButton(text, func() {
+			u.openTask(t)
+		})
+		b.Alignment = widget.ButtonAlignLeading
+		b.Importance = widget.LowImportance
+		u.dueBanner.Add(b)
+	}
+	u.dueBanner.Show()
+	u.dueBanner.Refresh()
+}
+
+// calendarBadge returns the number of open tasks due on a day, for the calendar
+func calendarBadge(t time.Time) string {
+	if theTaskCatalogue == nil {
+		return ""
+	}
+	if n := len(theTaskCatalogue.Due(util.DayOf(t), util.DayOf(t))); n > 0 {
+		return strconv.Itoa(n)
+	}
+	return ""
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/widget"
+	"oddstream.cj/export"
+	"oddstream.cj/note"
+	"oddstream.cj/search"
+)
+
+// exportFound pops up the options for compiling the found notes into a single document,
+// which can then be copied to the clipboard or saved to a file
+func (u *ui) exportFound() {
+	var pu *widget.PopUp
+
+	theFoundLock.Lock()
+	found := append([]*note.Note{}, theFound...)
+	theFoundLock.Unlock()
+	if len(found) == 0 {
+		return
+	}
+	u.saveNote() // so the note being edited is exported as it is in the editor
+
+	format := widget.NewRadioGroup([]string{"Markdown", "Plain text"}, nil)
+	format.Horizontal = true
+	format.SetSelected("Markdown")
+	which := widget.NewRadioGroup([]string{"All found notes", "Selected note"}, nil)
+	which.Horizontal = true
+	which.SetSelected("All found notes")
+	if u.foundSelected < 0 || u.foundSelected >= len(found) {
+		which.Disable()
+	}
+	matching := widget.NewCheck("Only matching paragraphs", nil)
+
+	document := func() string {
+		notes := found
+		if which.Selected == "Selected note" {
+			notes = found[u.foundSelected : u.foundSelected+1]
+		}
+		q, _ := search.ParseQuery(theSearch.Query, time.Now()) // a query that doesn't parse finds nothing anyway
+		q.Grammar = theGrammar
+		return export.Document(notes, export.Options{
+			Markdown:     format.Selected == "Markdown",
+			MatchingOnly: matching.Checked,
+			Query:        q,
+			Journals:     theSearch.AllJournals,
+		})
+	}
+
+	copyButton := widget.NewButton("Copy", func() {
+		u.mainWindow.Clipboard().SetContent(document())
+		pu.Hide()
+	})
+	saveButton := widget.NewButton("Save", func() {
+		pu.Hide()
+		d := dialog.NewFileSave(func(w fyne.URIWriteCloser, err error) {
+			if err != nil {
+				dialog.ShowError(err, u.mainWindow)
+				return
+			}
+			if w == nil {
+				return // cancelled
+			}
+			defer w.Close()
+			if _, err := w.Write([]byte(document())); err != nil {
+				dialog.ShowError(err, u.mainWindow)
+			}
+		}, u.mainWindow)
+		if format.Selected == "Markdown" {
+			d.SetFileName("found.md")
+		} else {
+			d.SetFileName("found.txt")
+		}
+		d.Show()
+	})
+	cancelButton := widget.NewButton("Cancel", func() {
+		pu.Hide()
+	})
+
+	content := container.New(layout.NewVBoxLayout(),
+		widget.NewLabel("Export Found Notes"),
+		format,
+		which,
+		matching,
+		container.New(layout.NewHBoxLayout(), copyButton, saveButton, cancelButton),
+	)
+	pu = widget.NewModalPopUp(content, u.mainWindow.Canvas())
+	pu.Show()
+}
@@ -0,0 +1,65 @@
+package export
+
+import (
+	"path/filepath"
+	"strings"
+
+	"oddstream.cj/note"
+	"oddstream.cj/search"
+)
+
+// Options control how notes are compiled into a document
+type Options struct {
+	Markdown     bool         // markdown headings, rather than plain text ones
+	MatchingOnly bool         // only the passages of each note that matter to Query, rather than the whole note
+	Query        search.Query // the query that found the notes (see search.Passages)
+	Journals     bool         // name the journal of each note in its heading
+}
+
+// Document compiles the notes, in the order given, into a single document.
+// Like the gawk script in the README, markdown gets a # heading whenever the year changes,
+// a ## heading whenever the month changes, and a ### heading for each note.
+// Notes with nothing to show, because none of their passages matter to the query, are left out.
+func Document(notes []*note.Note, opts Options) string {
+	var b strings.Builder
+	prevYear, prevMonth := 0, 0
+	for _, n := range notes {
+		text := body(n, opts)
+		if text == "" {
+			continue
+		}
+		heading := filepath.Base(n.Pathname) // undated
+		if n.Date.Year() != 1 {
+			heading = n.Date.Format("Monday 02 January 2006")
+		}
+		if opts.Journals {
+			heading += " (" + n.Journal + ")"
+		}
+		if opts.Markdown {
+			if y := n.Date.Year(); y != 1 && y != prevYear {
+				b.WriteString("\n# " + n.Date.Format("2006") + "\n")
+				prevYear, prevMonth = y, 0
+			}
+			if m := int(n.Date.Month()); n.Date.Year() != 1 && m != prevMonth {
+				b.WriteString("\n## " + n.Date.Format("January") + "\n")
+				prevMonth = m
+			}
+			heading = "### " + heading
+		}
+		b.WriteString("\n" + heading + "\n\n")
+		b.WriteString(text + "\n")
+	}
+	return strings.TrimPrefix(b.String(), "\n")
+}
+
+// body returns the text of a note as it is on disk, or just the passages that matter to the query
+func body(n *note.Note, opts Options) string {
+	if !opts.MatchingOnly {
+		return strings.TrimRight(n.Stored(), " \t\r\n")
+	}
+	var paras []string
+	for _, p := range search.Passages(n, opts.Query) {
+		paras = append(paras, p.Text)
+	}
+	return strings.Join(paras, "\n\n")
+}
@@ -0,0 +1,87 @@
+package export
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"oddstream.cj/note"
+	"oddstream.cj/search"
+)
+
+// writeNotes writes the texts of notes, keyed by their pathnames within a journal called Default,
+// and returns the notes in the order of pathnames
+func writeNotes(t *testing.T, pathnames []string, texts map[string]string) []*note.Note {
+	t.Helper()
+	directory := filepath.Join(t.TempDir(), "Default")
+	var notes []*note.Note
+	for _, p := range pathnames {
+		pathname := filepath.Join(directory, p)
+		if err := os.MkdirAll(filepath.Dir(pathname), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(pathname, []byte(texts[p]), 0644); err != nil {
+			t.Fatal(err)
+		}
+		notes = append(notes, note.NewNote("Default", directory, pathname))
+	}
+	return notes
+}
+
+func TestDocument(t *testing.T) {
+	notes := writeNotes(t, []string{"2023/07/01.txt", "2023/07/02.txt", "2023/08/01.txt", "2024/01/01.txt"}, map[string]string{
+		"2023/07/01.txt": "first\n\n",
+		"2023/07/02.txt": "second\r\n",
+		"2023/08/01.txt": "third",
+		"2024/01/01.txt": "fourth\n",
+	})
+	tests := []struct {
+		opts Options
+		want string
+	}{
+		{Options{},
+			"Saturday 01 July 2023\n\nfirst\n\n" +
+				"Sunday 02 July 2023\n\nsecond\n\n" +
+				"Tuesday 01 August 2023\n\nthird\n\n" +
+				"Monday 01 January 2024\n\nfourth\n"},
+		{Options{Markdown: true},
+			"# 2023\n\n## July\n\n### Saturday 01 July 2023\n\nfirst\n\n" +
+				"### Sunday 02 July 2023\n\nsecond\n\n" +
+				"## August\n\n### Tuesday 01 August 2023\n\nthird\n\n" +
+				"# 2024\n\n## January\n\n### Monday 01 January 2024\n\nfourth\n"},
+		{Options{Journals: true},
+			"Saturday 01 July 2023 (Default)\n\nfirst\n\n" +
+				"Sunday 02 July 2023 (Default)\n\nsecond\n\n" +
+				"Tuesday 01 August 2023 (Default)\n\nthird\n\n" +
+				"Monday 01 January 2024 (Default)\n\nfourth\n"},
+	}
+	for _, tt := range tests {
+		if got := Document(notes, tt.opts); got != tt.want {
+			t.Errorf("Document with %+v =\n%q\nwant\n%q", tt.opts, got, tt.want)
+		}
+	}
+}
+
+func TestDocumentMatchingOnly(t *testing.T) {
+	notes := writeNotes(t, []string{"2023/07/01.txt", "2023/07/02.txt", "2023/07/03.txt"}, map[string]string{
+		"2023/07/01.txt": "beans\n\nnot this\n\nmore beans\n",
+		"2023/07/02.txt": "#garden\nweeded\n\nshopping\n",
+		"2023/07/03.txt": "nothing\n",
+	})
+	notes[0].Hits = []note.Hit{{Line: 1}, {Line: 5}}
+	got := Document(notes, Options{MatchingOnly: true, Query: search.Query{Text: "beans"}})
+	if want := "Saturday 01 July 2023\n\nbeans\n\nmore beans\n"; got != want {
+		t.Errorf("Document of the hits = %q, want %q", got, want)
+	}
+	got = Document(notes, Options{MatchingOnly: true, Query: search.Query{Text: "#garden"}})
+	if want := "Sunday 02 July 2023\n\n#garden\nweeded\n"; got != want {
+		t.Errorf("Document of the tagged passages = %q, want %q", got, want)
+	}
+}
+
+func TestDocumentUndated(t *testing.T) {
+	notes := writeNotes(t, []string{"ideas.txt"}, map[string]string{"ideas.txt": "someday\n"})
+	if got, want := Document(notes, Options{Markdown: true}), "### ideas.txt\n\nsomeday\n"; got != want {
+		t.Errorf("Document of an undated note = %q, want %q", got, want)
+	}
+}
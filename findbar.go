@@ -0,0 +1,141 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+	"oddstream.cj/note"
+	"oddstream.cj/search"
+)
+
+var theFindIndex = -1 // index of the selected match in the current note, or -1 if none is selected
+
+// buildFindBar makes the bar above the note for finding text within it, hidden until wanted
+func (u *ui) buildFindBar() *fyne.Container {
+	u.findEntry = widget.NewEntry()
+	u.findEntry.PlaceHolder = "Find in note"
+	u.findEntry.OnChanged = func(string) {
+		theFindIndex = -1
+		u.findStep(1)
+	}
+	u.findEntry.OnSubmitted = func(string) {
+		u.findStep(1)
+	}
+	u.findCount = widget.NewLabel("")
+	prev := widget.NewButtonWithIcon("", theme.MoveUpIcon(), func() {
+		u.findStep(-1)
+	})
+	next := widget.NewButtonWithIcon("", theme.MoveDownIcon(), func() {
+		u.findStep(1)
+	})
+	hide := widget.NewButtonWithIcon("", theme.CancelIcon(), func() {
+		u.findBar.Hide()
+		u.mainWindow.Canvas().Focus(u.noteEntry)
+	})
+	buttons := container.New(layout.NewHBoxLayout(), u.findCount, prev, next, hide)
+	u.findBar = container.New(layout.NewBorderLayout(nil, nil, nil, buttons), buttons, u.findEntry)
+	u.findBar.Hide()
+	return u.findBar
+}
+
+// findMatches returns the matches of the find bar's text in the note as it is being edited
+func (u *ui) findMatches() []note.Match {
+	return search.Locate(u.noteEntry.Text, search.Query{Text: u.findEntry.Text, Fuzzy: theSearch.Fuzzy, Grammar: theGrammar})
+}
+
+// findStep selects the next (delta 1) or previous (delta -1) match in the note, wrapping around at the ends
+func (u *ui) findStep(delta int) {
+	matches := u.findMatches()
+	switch {
+	case u.findEntry.Text == "":
+		theFindIndex = -1
+		u.findCount.SetText("")
+		return
+	case len(matches) == 0:
+		theFindIndex = -1
+		u.findCount.SetText("0 of 0")
+		return
+	case theFindIndex < 0 && delta < 0:
+		theFindIndex = len(matches) - 1
+	case theFindIndex < 0:
+		theFindIndex = 0
+	default:
+		theFindIndex = (theFindIndex + delta + len(matches)) % len(matches)
+	}
+	u.selectMatch(matches[theFindIndex])
+	u.findCount.SetText(fmt.Sprintf("%d of %d", theFindIndex+1, len(matches)))
+}
+
+// selectMatch selects a match in the note, converting its byte offsets into the rune positions the entry uses
+func (u *ui) selectMatch(m note.Match) {
+	text := u.noteEntry.Text
+	start := utf8.RuneCountInString(text[:m.Start])
+	u.noteEntry.Select(start, start+utf8.RuneCountInString(text[m.Start:m.End]))
+}
+
+// setFindText puts str in the find bar without searching for it
+func (u *ui) setFindText(str string) {
+	onChanged := u.findEntry.OnChanged
+	u.findEntry.OnChanged = nil
+	u.findEntry.SetText(str)
+	u.findEntry.OnChanged = onChanged
+}
+
+// showFindBar opens the find bar on the selected text, or the text being searched for,
+// and selects the first match in the note
+func (u *ui) showFindBar() {
+	str := u.noteEntry.SelectedText()
+	if str == "" || strings.Contains(str, "\n") {
+		if q, err := search.ParseQuery(u.searchEntry.Text, time.Now()); err == nil {
+			str = q.Text
+		}
+	}
+	u.setFindText(str)
+	u.findBar.Show()
+	theFindIndex = -1
+	u.findStep(1)
+	u.mainWindow.Canvas().Focus(u.findEntry)
+}
+
+// jumpToHit selects the first match of the search at or after a line of the current note,
+// so a note opened from the found list shows why it was found
+func (u *ui) jumpToHit(line int) {
+	q, err := search.ParseQuery(theSearch.Query, time.Now())
+	if err != nil || q.Text == "" {
+		return
+	}
+	u.setFindText(q.Text)
+	u.findBar.Show()
+	matches := u.findMatches()
+	if len(matches) == 0 {
+		theFindIndex = -1
+		u.findCount.SetText("0 of 0")
+		return
+	}
+	var offset int // of the start of the line
+	for i, s := range strings.SplitAfter(u.noteEntry.Text, "\n") {
+		if i+1 >= line {
+			break
+		}
+		offset += len(s)
+	}
+	theFindIndex = len(matches) - 1
+	for i, m := range matches {
+		if m.Start >= offset {
+			theFindIndex = i
+			break
+		}
+	}
+	theFindIndex-- // findStep moves on to it
+	if theFindIndex < 0 {
+		theFindIndex = len(matches) - 1
+	}
+	u.findStep(1)
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"regexp"
+	"strings"
+
+	"fyne.io/fyne/v2"
+)
+
+// checkBoxPattern is a box that can be ticked, as at the start of a list item like [ ] buy milk
+var checkBoxPattern = regexp.MustCompile(`\[[ xX]\]`)
+
+// followCaret acts on the text at the cursor in the note, when it is ctrl+clicked or Ctrl+Enter is typed;
+// a hashtag is searched for, and a [ ] box is ticked, or a ticked one cleared
+func (u *ui) followCaret() {
+	text := u.noteEntry.Text
+	pos := byteOffset(text, u.noteEntry.Caret())
+	if t, ok := theGrammar.At(text, pos); ok {
+		u.searchForTag(t.Name)
+	} else if i, ok := checkBoxAt(text, pos); ok {
+		u.toggleCheckBox(i)
+	}
+}
+
+// caretMenuItems are the things that can be done to the text at the cursor, for the note's context menu
+func (u *ui) caretMenuItems() []*fyne.MenuItem {
+	text := u.noteEntry.Text
+	pos := byteOffset(text, u.noteEntry.Caret())
+	if t, ok := theGrammar.At(text, pos); ok {
+		return []*fyne.MenuItem{
+			fyne.NewMenuItem("Search for "+t.Name, func() {
+				u.searchForTag(t.Name)
+			}),
+			fyne.NewMenuItem("Show usage of "+t.Name, func() {
+				u.showTagUsage(t.Key)
+			}),
+		}
+	}
+	if i, ok := checkBoxAt(text, pos); ok {
+		label := "Tick"
+		if text[i+1] != ' ' {
+			label = "Untick"
+		}
+		return []*fyne.MenuItem{fyne.NewMenuItem(label, func() {
+			u.toggleCheckBox(i)
+		})}
+	}
+	return nil
+}
+
+// checkBoxAt returns the byte offset of the [ ] or [X] box in text at byte offset pos
+func checkBoxAt(text string, pos int) (int, bool) {
+	start := strings.LastIndexByte(text[:pos], '\n') + 1
+	end := len(text)
+	if i := strings.IndexByte(text[pos:], '\n'); i >= 0 {
+		end = pos + i
+	}
+	for _, m := range checkBoxPattern.FindAllStringIndex(text[start:end], -1) {
+		if start+m[0] <= pos && pos <= start+m[1] {
+			return start + m[0], true
+		}
+	}
+	return 0, false
+}
+
+// toggleCheckBox ticks the box at byte offset i in the note being edited, or clears it if it is ticked
+func (u *ui) toggleCheckBox(i int) {
+	text := u.noteEntry.Text
+	mark := "X"
+	if text[i+1] != ' ' {
+		mark = " "
+	}
+	row, col := u.noteEntry.CursorRow, u.noteEntry.CursorColumn
+	u.noteEntry.SetText(text[:i+1] + mark + text[i+2:])
+	u.noteEntry.CursorRow, u.noteEntry.CursorColumn = row, col
+	u.noteEntry.Refresh()
+}
+
+// byteOffset converts a rune position in text to a byte offset
+func byteOffset(text string, pos int) int {
+	for i := range text {
+		if pos == 0 {
+			return i
+		}
+		pos--
+	}
+	return len(text)
+}
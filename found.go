@@ -0,0 +1,208 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+	"oddstream.cj/note"
+	"oddstream.cj/search"
+)
+
+const (
+	snippetsPerItem = 2  // number of hit lines shown under each found note
+	snippetWidth    = 40 // width of each snippet in runes
+)
+
+// foundItem is a row in the found list, showing the date of a note,
+// which journal it's in when searching all journals,
+// how many hits it has, and snippets of the lines that matched
+type foundItem struct {
+	widget.BaseWidget
+	date     *widget.Label
+	journal  *widget.Label
+	count    *widget.Label
+	more     *widget.Button
+	snippets *widget.RichText
+	n        *note.Note
+}
+
+func newFoundItem() *foundItem {
+	fi := &foundItem{
+		date:     widget.NewLabel(""),
+		journal:  widget.NewLabel(""),
+		count:    widget.NewLabel(""),
+		snippets: widget.NewRichText(),
+	}
+	fi.date.TextStyle = fyne.TextStyle{Bold: true}
+	fi.more = widget.NewButtonWithIcon("", theme.MoreHorizontalIcon(), func() {
+		if fi.n != nil {
+			theUI.showHits(fi.n)
+		}
+	})
+	fi.more.Importance = widget.LowImportance
+	fi.snippets.Wrapping = fyne.TextTruncate
+	// size the template for a full set of snippets
+	var blank []note.Hit
+	for i := 0; i < snippetsPerItem; i++ {
+		blank = append(blank, note.Hit{Text: " "})
+	}
+	fi.snippets.Segments = hitSegments(blank)
+	fi.ExtendBaseWidget(fi)
+	return fi
+}
+
+func (fi *foundItem) CreateRenderer() fyne.WidgetRenderer {
+	left := container.New(layout.NewHBoxLayout(), fi.date, fi.journal)
+	right := container.New(layout.NewHBoxLayout(), fi.count, fi.more)
+	top := container.New(layout.NewBorderLayout(nil, nil, left, right), left, right)
+	return widget.NewSimpleRenderer(container.New(layout.NewVBoxLayout(), top, fi.snippets))
+}
+
+func (fi *foundItem) update(n *note.Note) {
+	fi.n = n
+	fi.date.SetText(noteTitle(n))
+	if theSearch.AllJournals {
+		fi.journal.SetText(n.Journal)
+		fi.journal.Show()
+	} else {
+		fi.journal.Hide()
+	}
+	count := hitCountText(n.HitCount())
+	if n.Score > 0 {
+		count = fmt.Sprintf("%s · %.2f", count, n.Score)
+	}
+	if variants := n.Variants(); len(variants) > 0 {
+		// show which near misses matched
+		count = fmt.Sprintf("%s ≈%s", count, strings.Join(variants, ","))
+	}
+	fi.count.SetText(count)
+	if len(n.Hits) > 0 {
+		fi.more.Show()
+	} else {
+		fi.more.Hide()
+	}
+	var hits []note.Hit
+	for i := 0; i < snippetsPerItem; i++ {
+		if i < len(n.Hits) {
+			hits = append(hits, search.Snippet(n.Hits[i], snippetWidth))
+		} else {
+			hits = append(hits, note.Hit{Text: " "})
+		}
+	}
+	fi.snippets.Segments = hitSegments(hits)
+	fi.snippets.Refresh()
+}
+
+// noteTitle is the date of a note, or its pathname if it is undated
+func noteTitle(n *note.Note) string {
+	if n.Date.Year() == 1 {
+		// "no date" shows as Mon 1 Jan 0001
+		return n.Pathname
+	}
+	return n.Date.Format("Mon 2 Jan 2006")
+}
+
+func hitCountText(count int) string {
+	switch count {
+	case 0:
+		return ""
+	case 1:
+		return "1 hit"
+	default:
+		return fmt.Sprintf("%d hits", count)
+	}
+}
+
+// hitSegments turns each hit into a line of rich text, with exact matches shown in bold
+// and fuzzy matches in italics
+func hitSegments(hits []note.Hit) []widget.RichTextSegment {
+	var segs []widget.RichTextSegment
+	for _, h := range hits {
+		var parts []*widget.TextSegment
+		prev := 0
+		for _, m := range mergeMatches(h.Matches) {
+			if m.Start > prev {
+				parts = append(parts, &widget.TextSegment{Style: widget.RichTextStyleInline, Text: h.Text[prev:m.Start]})
+			}
+			style := widget.RichTextStyleStrong
+			if m.Fuzzy {
+				style = widget.RichTextStyleEmphasis
+			}
+			parts = append(parts, &widget.TextSegment{Style: style, Text: h.Text[m.Start:m.End]})
+			prev = m.End
+		}
+		if prev < len(h.Text) || len(parts) == 0 {
+			parts = append(parts, &widget.TextSegment{Style: widget.RichTextStyleInline, Text: h.Text[prev:]})
+		}
+		// the last segment of each hit ends the line
+		parts[len(parts)-1].Style.Inline = false
+		for _, p := range parts {
+			segs = append(segs, p)
+		}
+	}
+	return segs
+}
+
+// showHits pops up every line of a note that matched the search
+func (u *ui) showHits(n *note.Note) {
+	var pu *widget.PopUp
+	hdr := widget.NewLabel(fmt.Sprintf("%s, %s", noteTitle(n), hitCountText(n.HitCount())))
+	lbox := widget.NewList(
+		func() int {
+			return len(n.Hits)
+		},
+		func() fyne.CanvasObject {
+			rt := widget.NewRichText()
+			rt.Wrapping = fyne.TextTruncate
+			return rt
+		},
+		func(id widget.ListItemID, obj fyne.CanvasObject) {
+			h := n.Hits[id]
+			h.Text = fmt.Sprintf("%d: %s", h.Line, h.Text)
+			offset := len(h.Text) - len(n.Hits[id].Text)
+			var matches []note.Match
+			for _, m := range n.Hits[id].Matches {
+				matches = append(matches, note.Match{Start: m.Start + offset, End: m.End + offset, Fuzzy: m.Fuzzy})
+			}
+			h.Matches = matches
+			rt := obj.(*widget.RichText)
+			rt.Segments = hitSegments([]note.Hit{h})
+			rt.Refresh()
+		},
+	)
+	lbox.OnSelected = func(id widget.ListItemID) {
+		u.saveNote()
+		u.setCurrentNote(n)
+		u.jumpToHit(n.Hits[id].Line)
+		pu.Hide()
+	}
+	cancel := widget.NewButton("Close", func() {
+		pu.Hide()
+	})
+	content := container.New(layout.NewBorderLayout(hdr, cancel, nil, nil), hdr, lbox, cancel)
+	pu = widget.NewModalPopUp(content, u.mainWindow.Canvas())
+	pu.Resize(fyne.NewSize(480, 320))
+	pu.Show()
+}
+
+// mergeMatches joins the matches, in order of where they start, that overlap, as an exact and a fuzzy match can,
+// so the text they share is only shown once; a joined match is exact if any of its parts are
+func mergeMatches(matches []note.Match) []note.Match {
+	var merged []note.Match
+	for _, m := range matches {
+		if last := len(merged) - 1; last >= 0 && m.Start < merged[last].End {
+			if m.End > merged[last].End {
+				merged[last].End = m.End
+			}
+			merged[last].Fuzzy = merged[last].Fuzzy && m.Fuzzy
+			continue
+		}
+		merged = append(merged, m)
+	}
+	return merged
+}
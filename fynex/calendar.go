@@ -1,11 +1,13 @@
 package fynex
 
 import (
+	"image/color"
 	"math"
 	"strconv"
 	"time"
 
 	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
 	"fyne.io/fyne/v2/container"
 	"fyne.io/fyne/v2/layout"
 	"fyne.io/fyne/v2/theme"
@@ -74,6 +76,15 @@ func (g *calendarLayout) MinSize(objects []fyne.CanvasObject) fyne.Size {
 	return fyne.NewSize(240, 240)
 }
 
+// DayDecoration is how a day is shown in a Calendar
+type DayDecoration struct {
+	HasNote  bool        // something has been written on the day, shown by a dot
+	Selected bool        // shown as a highlighted button
+	Today    bool        // shown with an outline
+	Color    color.Color // drawn behind the day, or nil for none
+	Badge    string      // a few characters for the top right corner, like a count
+}
+
 // Calendar creates a new date time picker which returns a time object
 type Calendar struct {
 	widget.BaseWidget
@@ -85,8 +96,8 @@ type Calendar struct {
 
 	dates *fyne.Container
 
-	onSelected  func(time.Time)
-	isImportant func(time.Time) bool
+	onSelected func(time.Time)
+	decorate   func(time.Time) DayDecoration
 }
 
 func (c *Calendar) daysOfMonth() []fyne.CanvasObject {
@@ -112,17 +123,60 @@ func (c *Calendar) daysOfMonth() []fyne.CanvasObject {
 			selectedDate := c.dateForButton(dayNum)
 			c.onSelected(selectedDate)
 		})
-		if c.isImportant(time.Date(c.currentTime.Year(), c.currentTime.Month(), dayNum, 0, 0, 0, 0, c.currentTime.Location())) {
+		date := time.Date(c.currentTime.Year(), c.currentTime.Month(), dayNum, 0, 0, 0, 0, c.currentTime.Location())
+		var dd DayDecoration
+		if c.decorate != nil {
+			dd = c.decorate(date)
+		}
+		if dd.Selected {
 			b.Importance = widget.HighImportance
 		} else {
 			b.Importance = widget.LowImportance
 		}
-		buttons = append(buttons, b)
+		day := []fyne.CanvasObject{b}
+		if dd.Color != nil {
+			// a low importance button is transparent, so the color shows through
+			day = append([]fyne.CanvasObject{canvas.NewRectangle(dd.Color)}, day...)
+		}
+		if dd.Today {
+			outline := canvas.NewRectangle(color.Transparent)
+			outline.StrokeColor = theme.PrimaryColor()
+			outline.StrokeWidth = 1
+			day = append(day, outline)
+		}
+		if dd.HasNote {
+			day = append(day, newDot())
+		}
+		if dd.Badge != "" {
+			day = append(day, newBadge(dd.Badge))
+		}
+		if len(day) == 1 {
+			buttons = append(buttons, b)
+		} else {
+			buttons = append(buttons, container.NewMax(day...))
+		}
 	}
 
 	return buttons
 }
 
+// newBadge makes a little label for the top right corner of a day
+func newBadge(text string) fyne.CanvasObject {
+	t := canvas.NewText(text, theme.ErrorColor())
+	t.TextSize = theme.CaptionTextSize()
+	t.TextStyle = fyne.TextStyle{Bold: true}
+	top := container.New(layout.NewHBoxLayout(), layout.NewSpacer(), t)
+	return container.New(layout.NewVBoxLayout(), top, layout.NewSpacer())
+}
+
+// newDot makes a little dot for the bottom of a day
+func newDot() fyne.CanvasObject {
+	dot := canvas.NewCircle(theme.ForegroundColor())
+	size := theme.Padding()
+	bottom := container.New(layout.NewHBoxLayout(), layout.NewSpacer(), container.New(layout.NewGridWrapLayout(fyne.NewSize(size, size)), dot), layout.NewSpacer())
+	return container.New(layout.NewVBoxLayout(), layout.NewSpacer(), bottom)
+}
+
 func (c *Calendar) dateForButton(dayNum int) time.Time {
 	oldName, off := c.currentTime.Zone()
 	return time.Date(c.currentTime.Year(), c.currentTime.Month(), dayNum, c.currentTime.Hour(), c.currentTime.Minute(), 0, 0, time.FixedZone(oldName, off)).In(c.currentTime.Location())
@@ -180,12 +234,12 @@ func (c *Calendar) CreateRenderer() fyne.WidgetRenderer {
 	return widget.NewSimpleRenderer(dateContainer)
 }
 
-// NewCalendar creates a calendar instance
-func NewCalendar(cT time.Time, onSelected func(time.Time), isImportant func(time.Time) bool) *Calendar {
+// NewCalendar creates a calendar instance; if decorate is not nil, it says how to show each day
+func NewCalendar(cT time.Time, onSelected func(time.Time), decorate func(time.Time) DayDecoration) *Calendar {
 	c := &Calendar{
 		currentTime: cT,
 		onSelected:  onSelected,
-		isImportant: isImportant,
+		decorate:    decorate,
 	}
 
 	c.ExtendBaseWidget(c)
@@ -0,0 +1,235 @@
+package fynex
+
+import (
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/widget"
+)
+
+// maxCompletionsShown is how many completions fit in the popup before it scrolls
+const maxCompletionsShown = 8
+
+// CompletionEntry is an entry that pops up a list of completions as the user types,
+// and steps back through a history of earlier entries with the up and down keys
+type CompletionEntry struct {
+	widget.Entry
+	History func() []string // earlier entries, most recent first
+
+	options      []string
+	popup        *widget.PopUp
+	list         *completionList
+	historyIndex int  // which history entry is shown, or -1
+	pause        bool // don't show completions while the text is being set from code
+}
+
+func NewCompletionEntry() *CompletionEntry {
+	c := &CompletionEntry{historyIndex: -1}
+	c.ExtendBaseWidget(c)
+	return c
+}
+
+// SetOptions sets the completions, showing them if there are any and hiding them if not
+func (c *CompletionEntry) SetOptions(options []string) {
+	c.options = options
+	if len(options) == 0 || c.pause {
+		c.HideCompletion()
+		return
+	}
+	c.showCompletion()
+}
+
+// HideCompletion hides the completions, if they are showing
+func (c *CompletionEntry) HideCompletion() {
+	if c.popup != nil {
+		c.popup.Hide()
+	}
+}
+
+func (c *CompletionEntry) showCompletion() {
+	cnv := fyne.CurrentApp().Driver().CanvasForObject(c)
+	if cnv == nil {
+		return
+	}
+	if f := cnv.Focused(); f != c && (c.list == nil || f != c.list) {
+		return // the text was set by something other than the user typing
+	}
+	if c.popup == nil {
+		c.list = newCompletionList(c)
+		c.popup = widget.NewPopUp(c.list, cnv)
+	}
+	c.list.reset()
+	pos := fyne.CurrentApp().Driver().AbsolutePositionForObject(c)
+	height := c.list.itemHeight() * float32(minInt(len(c.options), maxCompletionsShown))
+	c.popup.ShowAtPosition(pos.Add(fyne.NewPos(0, c.Size().Height)))
+	c.popup.Resize(fyne.NewSize(c.Size().Width, height))
+	// the popup takes the keyboard, so the list must pass typing on to the entry
+	cnv.Focus(c.list)
+}
+
+// TypedKey steps through the history with the up and down keys, otherwise behaving like widget.Entry
+func (c *CompletionEntry) TypedKey(key *fyne.KeyEvent) {
+	switch key.Name {
+	case fyne.KeyUp, fyne.KeyDown:
+		if c.History == nil {
+			return
+		}
+		history := c.History()
+		i := c.historyIndex + 1
+		if key.Name == fyne.KeyDown {
+			i = c.historyIndex - 1
+		}
+		switch {
+		case i < 0:
+			c.historyIndex = -1
+			c.setText("")
+		case i < len(history):
+			c.historyIndex = i
+			c.setText(history[i])
+		}
+	default:
+		c.Entry.TypedKey(key)
+	}
+}
+
+// setText replaces the text without showing completions for it
+func (c *CompletionEntry) setText(s string) {
+	index := c.historyIndex
+	c.pause = true
+	c.SetText(s)
+	c.pause = false
+	c.HideCompletion()
+	c.historyIndex = index
+	c.CursorColumn = len([]rune(s))
+	c.Refresh()
+}
+
+// SetText sets the text and stops any stepping through the history
+func (c *CompletionEntry) SetText(s string) {
+	c.historyIndex = -1
+	c.Entry.SetText(s)
+}
+
+// complete replaces the text with a chosen completion
+func (c *CompletionEntry) complete(s string) {
+	c.pause = true
+	c.SetText(s)
+	c.pause = false
+	c.HideCompletion()
+	c.CursorColumn = len([]rune(s))
+	c.Refresh()
+	if cnv := fyne.CurrentApp().Driver().CanvasForObject(c); cnv != nil {
+		cnv.Focus(c)
+	}
+}
+
+// completer is a widget that shows completions in a completionList
+type completer interface {
+	TypedRune(rune)
+	TypedKey(*fyne.KeyEvent)
+	TypedShortcut(fyne.Shortcut)
+	HideCompletion()
+	completions() []string
+	complete(string)
+}
+
+func (c *CompletionEntry) completions() []string {
+	return c.options
+}
+
+// completionList shows the completions, and having the focus while it is shown,
+// passes on anything typed to the widget being completed
+type completionList struct {
+	widget.List
+	owner      completer
+	selected   widget.ListItemID
+	navigating bool // selecting with the keyboard, rather than by tapping
+}
+
+func newCompletionList(owner completer) *completionList {
+	l := &completionList{owner: owner, selected: -1}
+	l.Length = func() int {
+		return len(owner.completions())
+	}
+	l.CreateItem = func() fyne.CanvasObject {
+		return widget.NewLabel("")
+	}
+	l.UpdateItem = func(id widget.ListItemID, obj fyne.CanvasObject) {
+		obj.(*widget.Label).SetText(owner.completions()[id])
+	}
+	l.OnSelected = func(id widget.ListItemID) {
+		l.selected = id
+		if !l.navigating {
+			owner.complete(owner.completions()[id])
+		}
+	}
+	l.ExtendBaseWidget(l)
+	return l
+}
+
+// reset shows the completions afresh, with none selected
+func (l *completionList) reset() {
+	l.Unselect(l.selected)
+	l.selected = -1
+	l.Refresh()
+}
+
+func (l *completionList) itemHeight() float32 {
+	return widget.NewLabel("").MinSize().Height + 4 // allowing for the list's separators
+}
+
+func (l *completionList) FocusGained() {}
+
+func (l *completionList) FocusLost() {}
+
+func (l *completionList) TypedRune(r rune) {
+	l.owner.TypedRune(r)
+}
+
+func (l *completionList) TypedKey(key *fyne.KeyEvent) {
+	options := l.owner.completions()
+	switch key.Name {
+	case fyne.KeyDown:
+		if l.selected < len(options)-1 {
+			l.navigating = true
+			l.Select(l.selected + 1)
+			l.navigating = false
+		}
+	case fyne.KeyUp:
+		if l.selected > 0 {
+			l.navigating = true
+			l.Select(l.selected - 1)
+			l.navigating = false
+		} else {
+			l.Unselect(l.selected)
+			l.selected = -1
+		}
+	case fyne.KeyReturn, fyne.KeyEnter:
+		if l.selected >= 0 && l.selected < len(options) {
+			l.owner.complete(options[l.selected])
+		} else {
+			l.owner.HideCompletion()
+			l.owner.TypedKey(key)
+		}
+	case fyne.KeyEscape:
+		l.owner.HideCompletion()
+	default:
+		l.owner.TypedKey(key)
+	}
+}
+
+func (l *completionList) TypedShortcut(shortcut fyne.Shortcut) {
+	l.owner.TypedShortcut(shortcut)
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func minFloat(a, b float32) float32 {
+	if a < b {
+		return a
+	}
+	return b
+}
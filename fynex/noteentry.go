@@ -0,0 +1,256 @@
+package fynex
+
+import (
+	"unicode"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/driver/desktop"
+	"fyne.io/fyne/v2/widget"
+)
+
+// NoteEntry is a multi-line, word-wrapped entry for editing a note.
+// Unlike widget.Entry, it can select text from code, and it can have shortcuts of its own,
+// which matters because a focused entry swallows the canvas shortcuts.
+type NoteEntry struct {
+	widget.Entry
+	// TagCompletions, if set, is called with a hashtag as it is being typed, eg #me,
+	// and returns the tags to offer to complete it
+	TagCompletions func(prefix string) []string
+	// TagRune, if set, reports whether a rune can be part of a hashtag;
+	// otherwise hashtags are made of letters and digits
+	TagRune func(r rune) bool
+	// Follow, if set, is called when the text is ctrl+clicked, after the cursor has moved to the click
+	Follow func()
+	// MenuItems, if set, returns items for the context menu that act on the text at the cursor,
+	// which has moved to where the menu was asked for
+	MenuItems func() []*fyne.MenuItem
+
+	shortcuts map[string]func(fyne.Shortcut)
+	tag       string // the hashtag being typed, or ""
+	options   []string
+	popup     *widget.PopUp
+	list      *completionList
+}
+
+func NewNoteEntry() *NoteEntry {
+	e := &NoteEntry{shortcuts: make(map[string]func(fyne.Shortcut))}
+	e.MultiLine = true
+	e.Wrapping = fyne.TextWrapWord
+	e.ExtendBaseWidget(e)
+	return e
+}
+
+// AddShortcut calls fn when shortcut is typed while the entry has focus
+func (e *NoteEntry) AddShortcut(shortcut fyne.Shortcut, fn func(fyne.Shortcut)) {
+	e.shortcuts[shortcut.ShortcutName()] = fn
+}
+
+// TypedShortcut implements fyne.Shortcutable
+func (e *NoteEntry) TypedShortcut(shortcut fyne.Shortcut) {
+	if fn, ok := e.shortcuts[shortcut.ShortcutName()]; ok {
+		fn(shortcut)
+		return
+	}
+	e.tag = ""
+	e.HideCompletion()
+	e.Entry.TypedShortcut(shortcut)
+}
+
+// TypedRune notices when a hashtag is being typed, and offers completions for it
+func (e *NoteEntry) TypedRune(r rune) {
+	e.Entry.TypedRune(r)
+	switch {
+	case r == '#':
+		e.tag = "#"
+	case e.tag != "" && e.isTagRune(r):
+		e.tag += string(r)
+	default:
+		e.tag = ""
+	}
+	e.offerCompletions()
+}
+
+func (e *NoteEntry) isTagRune(r rune) bool {
+	if e.TagRune != nil {
+		return e.TagRune(r)
+	}
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// TypedKey follows backspacing over the hashtag being typed; any other key finishes it
+func (e *NoteEntry) TypedKey(key *fyne.KeyEvent) {
+	e.Entry.TypedKey(key)
+	if key.Name == fyne.KeyBackspace && len(e.tag) > 1 {
+		r := []rune(e.tag)
+		e.tag = string(r[:len(r)-1])
+	} else {
+		e.tag = ""
+	}
+	e.offerCompletions()
+}
+
+// MouseDown finishes any hashtag being typed, as the cursor may be moving away from it,
+// and follows the text clicked on if ctrl is held down
+func (e *NoteEntry) MouseDown(m *desktop.MouseEvent) {
+	e.tag = ""
+	e.HideCompletion()
+	e.Entry.MouseDown(m)
+	if m.Button == desktop.MouseButtonPrimary && m.Modifier == fyne.KeyModifierControl && e.Follow != nil {
+		e.Follow()
+	}
+}
+
+// TappedSecondary shows the usual context menu, with any items from MenuItems added to it
+func (e *NoteEntry) TappedSecondary(pe *fyne.PointEvent) {
+	var items []*fyne.MenuItem
+	if e.MenuItems != nil && !e.Disabled() && e.SelectedText() == "" {
+		items = e.MenuItems()
+	}
+	if len(items) == 0 {
+		e.Entry.TappedSecondary(pe)
+		return
+	}
+	cnv := fyne.CurrentApp().Driver().CanvasForObject(e)
+	if cnv == nil {
+		return
+	}
+	cnv.Focus(e)
+	clipboard := fyne.CurrentApp().Driver().AllWindows()[0].Clipboard()
+	items = append(items,
+		fyne.NewMenuItemSeparator(),
+		fyne.NewMenuItem("Cut", func() {
+			e.TypedShortcut(&fyne.ShortcutCut{Clipboard: clipboard})
+		}),
+		fyne.NewMenuItem("Copy", func() {
+			e.TypedShortcut(&fyne.ShortcutCopy{Clipboard: clipboard})
+		}),
+		fyne.NewMenuItem("Paste", func() {
+			e.TypedShortcut(&fyne.ShortcutPaste{Clipboard: clipboard})
+		}),
+		fyne.NewMenuItem("Select all", func() {
+			e.TypedShortcut(&fyne.ShortcutSelectAll{})
+		}),
+	)
+	pos := fyne.CurrentApp().Driver().AbsolutePositionForObject(e)
+	widget.ShowPopUpMenuAtPosition(fyne.NewMenu("", items...), cnv, pos.Add(pe.Position))
+}
+
+func (e *NoteEntry) offerCompletions() {
+	e.options = nil
+	if e.tag != "" && e.TagCompletions != nil {
+		e.options = e.TagCompletions(e.tag)
+	}
+	if len(e.options) == 0 {
+		e.HideCompletion()
+		return
+	}
+	cnv := fyne.CurrentApp().Driver().CanvasForObject(e)
+	if cnv == nil {
+		return
+	}
+	if e.popup == nil {
+		e.list = newCompletionList(e)
+		e.popup = widget.NewPopUp(e.list, cnv)
+	}
+	e.list.reset()
+	// widget.Entry doesn't say where its cursor is on the screen,
+	// so the completions go in the bottom left corner of the entry
+	height := e.list.itemHeight() * float32(minInt(len(e.options), maxCompletionsShown))
+	pos := fyne.CurrentApp().Driver().AbsolutePositionForObject(e)
+	e.popup.ShowAtPosition(pos.Add(fyne.NewPos(0, e.Size().Height-height)))
+	e.popup.Resize(fyne.NewSize(minFloat(e.Size().Width, 240), height))
+	// the popup takes the keyboard, so the list must pass typing on to the entry
+	cnv.Focus(e.list)
+}
+
+// HideCompletion hides the hashtag completions, if they are showing
+func (e *NoteEntry) HideCompletion() {
+	if e.popup != nil && e.popup.Visible() {
+		e.popup.Hide()
+	}
+}
+
+func (e *NoteEntry) completions() []string {
+	return e.options
+}
+
+// complete finishes typing the hashtag with a chosen one
+func (e *NoteEntry) complete(tag string) {
+	rest := []rune(tag)[len([]rune(e.tag)):]
+	e.tag = ""
+	e.HideCompletion()
+	for _, r := range rest {
+		e.Entry.TypedRune(r)
+	}
+	if cnv := fyne.CurrentApp().Driver().CanvasForObject(e); cnv != nil {
+		cnv.Focus(e)
+	}
+}
+
+// Caret returns the rune position in the text of the cursor
+func (e *NoteEntry) Caret() int {
+	if e.CursorRow == 0 {
+		return e.CursorColumn
+	}
+	row, col := e.CursorRow, e.CursorColumn
+	pos := e.rowStart(row) + col
+	e.CursorRow, e.CursorColumn = row, col // rowStart moves it
+	e.Refresh()
+	return pos
+}
+
+// Select selects the text between rune positions start and end, leaving the cursor at end.
+//
+// widget.Entry only knows about cursor rows and columns, and the rows are wrapped lines
+// that only it can measure, so the text position of each row is found by having the
+// entry select from it back to the start of the text.
+func (e *NoteEntry) Select(start, end int) {
+	e.clearSelection()
+	e.TypedKey(&fyne.KeyEvent{Name: fyne.KeyPageDown}) // moves to the last row
+	rows := e.CursorRow + 1
+	startRow, startCol := e.rowCol(start, rows)
+	endRow, endCol := e.rowCol(end, rows)
+
+	e.CursorRow, e.CursorColumn = startRow, startCol
+	e.KeyDown(&fyne.KeyEvent{Name: desktop.KeyShiftLeft})
+	e.TypedKey(&fyne.KeyEvent{Name: fyne.KeyRight}) // starts a selection at the cursor
+	e.CursorRow, e.CursorColumn = endRow, endCol
+	e.KeyUp(&fyne.KeyEvent{Name: desktop.KeyShiftLeft})
+	e.Refresh()
+}
+
+// clearSelection leaves the entry not selecting, whatever state it was in
+func (e *NoteEntry) clearSelection() {
+	e.KeyUp(&fyne.KeyEvent{Name: desktop.KeyShiftLeft})
+	e.TypedKey(&fyne.KeyEvent{Name: fyne.KeyLeft})
+}
+
+// rowCol converts a rune position in the text to a cursor row and column,
+// by binary searching for the last row starting at or before it
+func (e *NoteEntry) rowCol(pos int, rows int) (int, int) {
+	lo, hi := 0, rows-1
+	loStart := 0
+	for lo < hi {
+		mid := (lo + hi + 1) / 2
+		if s := e.rowStart(mid); s <= pos {
+			lo, loStart = mid, s
+		} else {
+			hi = mid - 1
+		}
+	}
+	return lo, pos - loStart
+}
+
+// rowStart returns the rune position in the text of the start of a row
+func (e *NoteEntry) rowStart(row int) int {
+	if row == 0 {
+		return 0
+	}
+	e.CursorRow, e.CursorColumn = row, 0
+	e.KeyDown(&fyne.KeyEvent{Name: desktop.KeyShiftLeft})
+	e.TypedKey(&fyne.KeyEvent{Name: fyne.KeyPageUp}) // selects back to the start of the text
+	e.KeyUp(&fyne.KeyEvent{Name: desktop.KeyShiftLeft})
+	n := len([]rune(e.SelectedText()))
+	e.TypedKey(&fyne.KeyEvent{Name: fyne.KeyLeft}) // clears the selection
+	return n
+}
@@ -1,10 +1,13 @@
 package fynex
 
 import (
+	"time"
+
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/container"
 	"fyne.io/fyne/v2/layout"
 	"fyne.io/fyne/v2/widget"
+	"oddstream.cj/util"
 )
 
 // func ShowListPopUp(canvas fyne.Canvas, title string, strs []string, okCallback func(string)) {
@@ -136,3 +139,82 @@ func ShowListEntryPopUp2(canvas fyne.Canvas, title string, strs []string, okCall
 // 	popupMenu.ShowAtPosition(parent.Position())
 // 	// popupMenu.Show()
 // }
+
+// ShowDateRangePopUp lets the user pick a range of dates on a calendar;
+// the first tap sets the start of the range, the second tap sets the end.
+// okCallback is called with zero times if the user chooses "Any date".
+func ShowDateRangePopUp(canvas fyne.Canvas, title string, from, to time.Time, okCallback func(time.Time, time.Time)) {
+	var pu *widget.PopUp
+	var picking bool // true after the start of the range has been tapped
+	hdr := widget.NewLabel(title)
+	rangeLabel := widget.NewLabel("")
+	holder := container.New(layout.NewCenterLayout())
+
+	var redraw func()
+	inRange := func(t time.Time) bool {
+		d := util.DayOf(t)
+		return !from.IsZero() && !d.Before(from) && !d.After(to)
+	}
+	tapped := func(t time.Time) {
+		d := util.DayOf(t)
+		if !picking || d.Before(from) {
+			from, to = d, d
+			picking = true
+		} else {
+			to = d
+			picking = false
+		}
+		redraw()
+	}
+	redraw = func() {
+		if from.IsZero() {
+			rangeLabel.SetText("Any date")
+		} else {
+			rangeLabel.SetText(from.Format("2 Jan 2006") + " - " + to.Format("2 Jan 2006"))
+		}
+		current := to
+		if current.IsZero() {
+			current = time.Now()
+		}
+		holder.Objects = []fyne.CanvasObject{NewCalendar(current, tapped, func(t time.Time) DayDecoration {
+			return DayDecoration{Selected: inRange(t)}
+		})}
+		holder.Refresh()
+	}
+	if !from.IsZero() && to.IsZero() {
+		to = util.DayOf(time.Now())
+	}
+	redraw()
+
+	today := util.DayOf(time.Now())
+	quick := func(label string, start time.Time) *widget.Button {
+		return widget.NewButton(label, func() {
+			from, to = start, today
+			picking = false
+			redraw()
+		})
+	}
+	quicks := container.New(layout.NewGridLayout(2),
+		quick("Last 7 days", today.AddDate(0, 0, -6)),
+		quick("Last 30 days", today.AddDate(0, 0, -29)),
+		quick("Last quarter", today.AddDate(0, -3, 0)),
+		quick("This year", time.Date(today.Year(), 1, 1, 0, 0, 0, 0, time.Local)),
+	)
+	anyDate := widget.NewButton("Any date", func() {
+		okCallback(time.Time{}, time.Time{})
+		pu.Hide()
+	})
+	ok := widget.NewButton("OK", func() {
+		okCallback(from, to)
+		pu.Hide()
+	})
+	cancel := widget.NewButton("Cancel", func() {
+		pu.Hide()
+	})
+	buttons := container.New(layout.NewGridLayout(3), ok, anyDate, cancel)
+	top := container.New(layout.NewVBoxLayout(), hdr, rangeLabel)
+	bottom := container.New(layout.NewVBoxLayout(), quicks, buttons)
+	content := container.New(layout.NewBorderLayout(top, bottom, nil, nil), top, holder, bottom)
+	pu = widget.NewModalPopUp(content, canvas)
+	pu.Show()
+}
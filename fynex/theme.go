@@ -30,6 +30,9 @@ var resourceFontTtf = &fyne.StaticResource{
 }
 */
 
+// ColorNameNote is the color drawn behind the note being edited; transparent unless the note has been tinted
+const ColorNameNote fyne.ThemeColorName = "note"
+
 type NoteTheme struct {
 	colors map[fyne.ThemeColorName]color.RGBA
 	sizes  map[fyne.ThemeSizeName]float32
@@ -78,9 +81,21 @@ func (nt *NoteTheme) Color(name fyne.ThemeColorName, variant fyne.ThemeVariant)
 	if rgba, ok := nt.colors[name]; ok {
 		return color.Color(rgba)
 	}
+	if name == ColorNameNote {
+		return color.Transparent
+	}
 	return theme.DefaultTheme().Color(name, variant)
 }
 
+// SetNoteColor tints the background of the note being edited, or clears the tint if c is nil
+func (nt *NoteTheme) SetNoteColor(c color.Color) {
+	if c == nil {
+		delete(nt.colors, ColorNameNote)
+		return
+	}
+	nt.colors[ColorNameNote] = color.RGBAModel.Convert(c).(color.RGBA)
+}
+
 func (nt *NoteTheme) Font(s fyne.TextStyle) fyne.Resource {
 	/*
 		bundleFont("NotoSans-Regular.ttf", "regular", f)
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"oddstream.cj/search"
+)
+
+// historyEntry is a query that was searched for, and when
+type historyEntry struct {
+	Query string    `json:"query"`
+	Time  time.Time `json:"time"`
+}
+
+const (
+	maxHistory     = 100 // how many queries to remember
+	maxSuggestions = 8   // how many completions to offer in the search entry
+)
+
+var (
+	theVocabulary   *search.Vocabulary                // the words and hashtags used in the current journal
+	theVocabularies = map[string]*search.Vocabulary{} // of the current journal and any others searched, by name
+)
+
+// addToHistory remembers a query as the most recent one searched for
+func addToHistory(query string) {
+	query = strings.TrimSpace(query)
+	if len(query) < 2 {
+		return
+	}
+	history := []historyEntry{{Query: query, Time: time.Now()}}
+	for _, h := range theSettings.History {
+		if h.Query != query && len(history) < maxHistory {
+			history = append(history, h)
+		}
+	}
+	theSettings.History = history
+	saveSettings()
+}
+
+// historyQueries returns the queries searched for, most recent first
+func historyQueries() []string {
+	var queries []string
+	for _, h := range theSettings.History {
+		queries = append(queries, h.Query)
+	}
+	return queries
+}
+
+// suggestions returns completions for a partly typed query; earlier queries it starts,
+// then hashtags or frequently used words completing its last word
+func suggestions(str string) []string {
+	var options []string
+	if strings.TrimSpace(str) == "" {
+		return options
+	}
+	seen := map[string]bool{str: true}
+	add := func(s string) {
+		if !seen[s] && len(options) < maxSuggestions {
+			seen[s] = true
+			options = append(options, s)
+		}
+	}
+	lower := strings.ToLower(str)
+	for _, q := range historyQueries() {
+		if strings.HasPrefix(strings.ToLower(q), lower) && len(options) < maxSuggestions/2 {
+			add(q)
+		}
+	}
+
+	// split str itself, as lower-casing can change the length of some letters
+	i := strings.LastIndexAny(str, " \t") + 1
+	word := strings.ToLower(str[i:])
+	if len(word) < 2 {
+		return options
+	}
+	counts := theVocabulary.Words()
+	if strings.HasPrefix(word, "#") {
+		counts = theVocabulary.Tags()
+	}
+	var completions []string
+	for w := range counts {
+		if strings.HasPrefix(w, word) && w != word {
+			completions = append(completions, w)
+		}
+	}
+	sort.Slice(completions, func(a, b int) bool {
+		if counts[completions[a]] != counts[completions[b]] {
+			return counts[completions[a]] > counts[completions[b]]
+		}
+		return completions[a] < completions[b]
+	})
+	for _, w := range completions {
+		add(str[:i] + w)
+	}
+	return options
+}
@@ -1,20 +1,20 @@
 package main
 
 import (
-	"bufio"
+	"context"
 	_ "embed"
 	"flag"
 	"fmt"
+	"image/color"
 	"log"
 	"os"
-	"os/exec"
 	"path"
-	"sort"
 	"strings"
 	"time"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/app"
+	"fyne.io/fyne/v2/canvas"
 	"fyne.io/fyne/v2/container"
 	"fyne.io/fyne/v2/dialog"
 	"fyne.io/fyne/v2/driver/desktop"
@@ -23,6 +23,9 @@ import (
 	"fyne.io/fyne/v2/widget"
 	"oddstream.cj/fynex"
 	"oddstream.cj/note"
+	"oddstream.cj/search"
+	"oddstream.cj/tags"
+	"oddstream.cj/tasks"
 	"oddstream.cj/util"
 )
 
@@ -41,18 +44,42 @@ var (
 	theJournalDir  string       // eg Default
 	theDirectory   string       // eg /home/gilbert/.cj/Default (no trailing path separator)
 	theNote        *note.Note   // the current note
+	theIndex       *note.Index  // feeds the notes of the current journal to the catalogues
 	theFound       []*note.Note // the list of found notes
+	theSortOrder   search.Order // how the found notes are sorted
+	theFuzzyMode   bool         // if true, searches also find near misses
+	theAllJournals bool         // if true, searches look in every journal, not just the current one
 	debugMode      bool
 )
 
 type ui struct {
-	mainWindow  fyne.Window // Window is an interface
-	toolbar     *widget.Toolbar
-	calendar    *fyne.Container //*Calendar
-	searchEntry *widget.Entry
-	foundList   *widget.List
-	noteEntry   *widget.Entry
-	theme       fyne.Theme // Theme is an interface
+	mainWindow    fyne.Window // Window is an interface
+	toolbar       *widget.Toolbar
+	calendar      *fyne.Container //*Calendar
+	searchEntry   *fynex.CompletionEntry
+	foundList     *widget.List
+	foundSelected widget.ListItemID // -1 if no found note is selected
+	breadcrumbs   *fyne.Container
+	foundCount    *widget.Label
+	searchBusy    *widget.ProgressBarInfinite
+	savedList     *widget.List
+	sideTabs      *container.AppTabs
+	noteEntry     *fynex.NoteEntry
+	tagPanel      *fyne.Container
+	tagDock       *widget.Button
+	mainPanel     *fyne.Container // the toolbar and the note, with the tag panel docked beside it
+	tagList       *widget.List
+	coTagLabel    *widget.Label
+	coTagList     *widget.List
+	findBar       *fyne.Container
+	findEntry     *widget.Entry
+	findCount     *widget.Label
+	taskList      *widget.List
+	taskFilter    *widget.RadioGroup
+	taskGroup     *widget.Select
+	dueBanner     *fyne.Container
+	noteTint      *canvas.Rectangle // behind noteEntry, showing the color of the note's tags
+	theme         *fynex.NoteTheme
 }
 
 func appTitle() string {
@@ -64,97 +91,83 @@ func (u *ui) displayText() {
 		theNote.Load()
 	}
 	u.noteEntry.SetText(theNote.Text)
+	u.addRecurring()
+	u.tintNote()
+	u.showDue()
 }
 
 func (u *ui) setCurrentNote(n *note.Note) {
 	theNote = n
 	u.displayText()
-	u.calendar.Objects[0] = fynex.NewCalendar(theNote.Date, calendarTapped, calendarIsDateImportant)
+	u.refreshCalendar()
 	u.mainWindow.SetTitle(appTitle())
 }
 
-func calendarTapped(t time.Time) {
-	theNote.SaveIfDirty(theUI.noteEntry.Text)
-	theUI.setCurrentNote(note.NewNote(theDirectory, t))
-	theUI.foundList.UnselectAll()
-	theUI.mainWindow.Canvas().Focus(theUI.noteEntry)
+// refreshCalendar shows the month of the current note in the calendar
+func (u *ui) refreshCalendar() {
+	u.calendar.Objects[0] = fynex.NewCalendar(theNote.Date, calendarTapped, calendarDecoration)
+	u.calendar.Refresh()
 }
 
-func calendarIsDateImportant(t time.Time) bool {
-	return t.Year() == theNote.Date.Year() &&
-		t.Month() == theNote.Date.Month() &&
-		t.Day() == theNote.Date.Day()
-}
-
-func find(query string) []*note.Note {
-	var found []*note.Note
-
-	if query == "" {
-		return found
-	}
-
-	// could use xargs to run several directories in parallel?
-	// expected output is a list of pathnames, one per line, eg
-	// /home/gilbert/.cj/Default/2023/07/04.txt
-	// /home/gilbert/.cj/Default/2023/06/18.txt
-	// could use ripgrep which is faster
-	cmd := exec.Command("grep", // becomes /usr/bin/grep
-		"--fixed-strings", // interpret PATTERNS as fixed strings, not regular expressions.
-		"--recursive",
-		"--ignore-case",
-		"--files-with-matches", // print the name of each input file from which output would normally have been printed.
-		// regexp.QuoteMeta(query), // don't quote meta if using --fixed-strings
-		"-I",                 // don't process binary files
-		"--exclude-dir='.*'", // exclude hidden directories
-		query,                // don't surround with quotes or double quotes
-		theDirectory)
-	// fmt.Println(cmd.Path)
-	// fmt.Println(cmd.Args)
-	stdout, err := cmd.StdoutPipe()
-	if err != nil {
-		log.Fatal(err)
-	}
-	err = cmd.Start()
+// saveNote saves the current note if it has been edited
+func (u *ui) saveNote() {
+	changed, err := theNote.SaveIfDirty(u.noteEntry.Text)
 	if err != nil {
-		log.Fatalf("cmd.Start() failed with %s\n", err)
+		dialog.ShowError(err, u.mainWindow)
 	}
-	stdin := bufio.NewScanner(stdout)
-	for stdin.Scan() {
-		pathname := stdin.Text()
-		// fmt.Println(pathname)
-		if strings.HasPrefix(pathname, ".") {
-			continue
-		}
-		n := note.NewNote(theDirectory, pathname)
-		found = append(found, n)
+	if changed {
+		u.notesChanged([]string{theNote.Pathname})
 	}
-	// fmt.Println("-----")
-	cmd.Wait() // ignore error return because we're done
+}
 
-	sort.Slice(found, func(i, j int) bool {
-		return found[i].Date.Before(found[j].Date)
-	})
+// reloadNote rereads the current note, after it has been changed on disk behind the editor's back
+func (u *ui) reloadNote() {
+	theNote.Load()
+	u.noteEntry.SetText(theNote.Text)
+	u.tintNote()
+}
 
-	return found
+// notesChanged updates anything that depends on the contents of the notes, after some have been changed
+func (u *ui) notesChanged(pathnames []string) {
+	for _, p := range pathnames {
+		theIndex.Update(p)
+	}
+	u.tagsChanged()
+	u.tasksChanged()
+	scheduleReminders()
+	u.tintNote()
+	u.refreshCalendar() // the tints and due dates of the days may have changed
+	u.refreshSavedSearches()
 }
 
-func daysBetween(m *note.Note, n *note.Note) int {
-	return int(n.Date.Sub(m.Date).Hours() / 24)
+func calendarTapped(t time.Time) {
+	theUI.saveNote()
+	theUI.setCurrentNote(note.NewNote(theJournalDir, theDirectory, t))
+	theUI.foundList.UnselectAll()
+	theUI.mainWindow.Canvas().Focus(theUI.noteEntry)
 }
 
-func contains(lst []*note.Note, b *note.Note) bool {
-	for _, a := range lst {
-		if daysBetween(a, b) == 0 {
-			return true
-		}
+// calendarDecoration shows which days have notes, the current note's day, today,
+// and the colors of the tags used and the number of tasks due on each day
+func calendarDecoration(t time.Time) fynex.DayDecoration {
+	return fynex.DayDecoration{
+		HasNote:  note.NewNote(theJournalDir, theDirectory, t).Exists(),
+		Selected: util.SameDay(t, theNote.Date),
+		Today:    util.SameDay(t, time.Now()),
+		Color:    calendarTint(t),
+		Badge:    calendarBadge(t),
 	}
-	return false
 }
 
 func (u *ui) postFind() {
-	if len(theFound) > 0 {
+	theFoundLock.Lock()
+	search.Sort(theFound, theSortOrder)
+	found := theFound
+	theFoundLock.Unlock()
+	if len(found) > 0 {
 		u.foundList.Select(0)
-		u.setCurrentNote(theFound[0])
+		u.setCurrentNote(found[0])
+		u.jumpToHit(1)
 	} else {
 		u.foundList.UnselectAll()
 	}
@@ -165,69 +178,55 @@ func (u *ui) findEx() {
 	var pu *widget.PopUp
 	var bfind, bwiden, bnarrow, bexclude, bcancel *widget.Button
 
+	// the found notes can be refined if there has been a search, even if it found nothing after refining
+	theFoundLock.Lock()
+	current := append(results{}, theResults...)
+	theFoundLock.Unlock()
+
 	ent := widget.NewEntry()
 	ent.PlaceHolder = "Search"
-	if len(theFound) == 0 {
+	// refineWith runs the query in the entry and combines its results with the found notes
+	refineWith := func(op string) {
+		r := current
+		if len(r) == 0 {
+			theSearch = newSearch(ent.Text)
+		}
+		sc, err := theSearch.scope()
+		if err != nil {
+			dialog.ShowError(err, u.mainWindow)
+			return
+		}
+		found, err := theSearch.find(context.Background(), sc, ent.Text, nil)
+		if err != nil {
+			dialog.ShowError(err, u.mainWindow)
+			return
+		}
+		if len(r) == 0 && len(found) == 0 {
+			return // nothing to show, so leave the popup up to try again
+		}
+		// otherwise record the step even if it found nothing; narrowing by nothing leaves nothing,
+		// and widening or excluding by nothing leaves the found notes as they are
+		if len(r) > 0 {
+			theSearch.Name = "" // no longer the same as any saved search
+			theSearch.Steps = append(theSearch.Steps, refinement{Op: op, Query: ent.Text})
+		}
+		u.setResults(append(r, found))
+		u.postFind()
+		pu.Hide()
+	}
+	if len(current) == 0 {
 		bfind = widget.NewButton("Find", func() {
-			results := find(ent.Text)
-			if len(results) == 0 {
-				return
-			}
-			var newFound []*note.Note = theFound
-			for _, n := range results {
-				if !contains(theFound, n) {
-					newFound = append(newFound, n)
-				}
-			}
-			theFound = newFound
-			u.postFind()
-			pu.Hide()
+			refineWith(opWiden)
 		})
 	} else {
 		bwiden = widget.NewButton("Widen", func() {
-			results := find(ent.Text)
-			if len(results) == 0 {
-				return
-			}
-			var newFound []*note.Note = theFound
-			for _, n := range results {
-				if !contains(theFound, n) {
-					newFound = append(newFound, n)
-				}
-			}
-			theFound = newFound
-			u.postFind()
-			pu.Hide()
+			refineWith(opWiden)
 		})
 		bnarrow = widget.NewButton("Narrow", func() {
-			results := find(ent.Text)
-			if len(results) == 0 {
-				return
-			}
-			var newFound []*note.Note
-			for _, n := range results {
-				if contains(theFound, n) {
-					newFound = append(newFound, n)
-				}
-			}
-			theFound = newFound
-			u.postFind()
-			pu.Hide()
+			refineWith(opNarrow)
 		})
 		bexclude = widget.NewButton("Exclude", func() {
-			results := find(ent.Text)
-			if len(results) == 0 {
-				return
-			}
-			var newFound []*note.Note
-			for _, n := range results {
-				if !contains(theFound, n) {
-					newFound = append(newFound, n)
-				}
-			}
-			theFound = newFound
-			u.postFind()
-			pu.Hide()
+			refineWith(opExclude)
 		})
 	}
 	bcancel = widget.NewButton("Cancel", func() {
@@ -245,26 +244,38 @@ func (u *ui) findEx() {
 	pu.Canvas.Focus(ent)
 }
 
-func (u *ui) promptUserForJournalDir() {
+// journalNames returns the names of all the journals in the data directory
+func journalNames() ([]string, error) {
 	var journalDirs []string
 
 	homePath := path.Join(theUserHomeDir, theDataDir)
 	f, err := os.Open(homePath)
 	if err != nil {
-		log.Fatalf("couldn't open path %s: %s\n", homePath, err)
+		return nil, fmt.Errorf("couldn't open path %s: %w", homePath, err)
 	}
+	defer f.Close()
 	dirNames, err := f.Readdirnames(-1)
 	if err != nil {
-		log.Fatalf("couldn't read dir names for path %s: %s\n", homePath, err)
+		return nil, fmt.Errorf("couldn't read dir names for path %s: %w", homePath, err)
 	}
 	for _, dirName := range dirNames {
 		if !strings.HasPrefix(dirName, ".") {
 			journalDirs = append(journalDirs, dirName)
 		}
 	}
+	return journalDirs, nil
+}
+
+func (u *ui) promptUserForJournalDir() {
+	journalDirs, err := journalNames()
+	if err != nil {
+		dialog.ShowError(err, u.mainWindow)
+		return
+	}
 	if len(journalDirs) == 1 {
-		theJournalDir = journalDirs[0]
-		theDirectory = path.Join(theUserHomeDir, theDataDir, theJournalDir)
+		if journalDirs[0] != theJournalDir {
+			u.setJournal(journalDirs[0])
+		}
 		return
 	}
 
@@ -278,50 +289,53 @@ func (u *ui) promptUserForJournalDir() {
 			return
 		}
 		if str != theJournalDir {
-			theJournalDir = str
-			theDirectory = path.Join(theUserHomeDir, theDataDir, theJournalDir)
-			calendarTapped(time.Now())
-			theFound = []*note.Note{}
-			u.foundList.Refresh()
+			u.setJournal(str)
 		}
 	})
 }
 
-func (u *ui) searchForHashTags() {
-	cmd := exec.Command("grep",
-		"--extended-regexp", // because we are using character class
-		"--recursive",
-		"--ignore-case",
-		"--only-matching",    // print only matching parts
-		"--no-filename",      // do not prefix output with file name
-		"-I",                 // don't process binary files
-		"--exclude-dir='.*'", // exclude hidden directories
-		"#[[:alnum:]]+",      // \w a word character: [0-9A-Za-z_]
-		theDirectory)
-	stdout, err := cmd.StdoutPipe()
-	if err != nil {
-		log.Fatal(err)
-	}
-	err = cmd.Start()
-	if err != nil {
-		log.Fatalf("cmd.Start() failed with %s\n", err)
-	}
-	stdin := bufio.NewScanner(stdout)
-	var results []string
-	for stdin.Scan() {
-		txt := stdin.Text()
-		txt = strings.ToLower(txt)
-		// fmt.Println(txt)
-		results = append(results, txt)
+// setJournal switches to another journal, opening today's note
+func (u *ui) setJournal(name string) {
+	u.switchJournal(name)
+	calendarTapped(time.Now())
+	theSearch = savedSearch{}
+	u.setResults(nil)
+}
+
+// switchJournal makes another journal the current one, leaving the found notes alone
+func (u *ui) switchJournal(name string) {
+	u.saveNote()
+	theJournalDir = name
+	theDirectory = path.Join(theUserHomeDir, theDataDir, theJournalDir)
+	loadSettings() // first, as the settings say what a hashtag can be
+	indexJournal()
+	u.dockTagPanel()
+	if u.tagPanel.Visible() {
+		u.refreshTags()
 	}
-	cmd.Wait() // ignore error return because we're done
-	if len(results) > 0 {
-		results = util.RemoveDuplicateStrings(results) // sorts slice as a side-effect
-		fynex.ShowListPopUp2(theUI.mainWindow.Canvas(), "Find Hashtag", results, func(str string) {
-			theFound = find(str)
-			theUI.postFind()
-		})
+	u.refreshTasks()
+	u.refreshSavedSearches()
+	scheduleReminders()
+}
+
+// indexJournal reads the notes of the current journal into new catalogues
+func indexJournal() {
+	theCatalogue = tags.NewCatalogue(theGrammar)
+	theTaskCatalogue = tasks.NewCatalogue(theGrammar)
+	theVocabulary = search.NewVocabulary(theGrammar)
+	theIndex = note.NewIndex(theJournalDir, theDirectory, theCatalogue, theTaskCatalogue, theVocabulary)
+	theVocabularies[theJournalDir] = theVocabulary
+}
+
+// promptUserForDateRange sets the after: and before: filters in the search entry
+func (u *ui) promptUserForDateRange() {
+	var from, to time.Time
+	if q, err := search.ParseQuery(u.searchEntry.Text, time.Now()); err == nil {
+		from, to = q.After, q.Before
 	}
+	fynex.ShowDateRangePopUp(u.mainWindow.Canvas(), "Search Dates", from, to, func(from, to time.Time) {
+		u.searchEntry.SetText(search.WithDateRange(u.searchEntry.Text, from, to))
+	})
 }
 
 func buildUI(u *ui) fyne.CanvasObject {
@@ -332,13 +346,16 @@ func buildUI(u *ui) fyne.CanvasObject {
 		}),
 		// widget.NewToolbarAction(theme.SearchIcon(), func() {
 		widget.NewToolbarAction(u.theme.Icon("tag"), func() {
-			theUI.searchForHashTags()
+			theUI.toggleTagPanel()
 		}),
 		widget.NewToolbarAction(theme.SearchIcon(), func() {
 			// if len(theFound) > 0 {
 			theUI.findEx()
 			// }
 		}),
+		widget.NewToolbarAction(theme.SearchReplaceIcon(), func() {
+			theUI.findAndReplace()
+		}),
 		widget.NewToolbarAction(u.theme.Icon("link"), func() {
 			if str := theUI.noteEntry.SelectedText(); str != "" {
 				if err := link(str); err != nil {
@@ -362,67 +379,159 @@ func buildUI(u *ui) fyne.CanvasObject {
 		}),
 	)
 
-	u.calendar = container.New(layout.NewCenterLayout(), fynex.NewCalendar(theNote.Date, calendarTapped, calendarIsDateImportant))
+	u.calendar = container.New(layout.NewCenterLayout(), fynex.NewCalendar(theNote.Date, calendarTapped, calendarDecoration))
 
-	u.searchEntry = widget.NewEntry()
+	u.searchEntry = fynex.NewCompletionEntry()
 	u.searchEntry.PlaceHolder = "Search"
+	u.searchEntry.History = historyQueries
 	u.searchEntry.OnChanged = func(str string) {
-		theFound = []*note.Note{}
-		if len(str) > 1 {
-			theFound = find(str)
-		}
-		u.foundList.UnselectAll()
-		u.foundList.Refresh()
+		u.liveSearch(newSearch(str))
+		u.searchEntry.SetOptions(suggestions(str))
+	}
+	u.searchEntry.OnSubmitted = func(str string) {
+		addToHistory(str)
 	}
-	// u.searchEntry.OnSubmitted = func(str string) {
-	// 	u.found = find(str)
-	// }
 	u.searchEntry.TextStyle = fyne.TextStyle{Monospace: true}
 
 	searchEntryClear := widget.NewButtonWithIcon("", theme.ContentClearIcon(), func() {
 		u.searchEntry.SetText("")
 		theUI.mainWindow.Canvas().Focus(theUI.searchEntry)
-		theFound = []*note.Note{}
+		theSearch = savedSearch{}
+		u.setResults(nil)
+		u.foundList.UnselectAll()
+	})
+
+	searchDates := widget.NewButtonWithIcon("", theme.HistoryIcon(), func() {
+		u.promptUserForDateRange()
+	})
+
+	var sortLabels []string
+	for _, o := range search.Orders {
+		sortLabels = append(sortLabels, o.String())
+	}
+	sortSelect := widget.NewSelect(sortLabels, func(str string) {
+		for _, o := range search.Orders {
+			if o.String() == str {
+				theSortOrder = o
+			}
+		}
+		theFoundLock.Lock()
+		search.Sort(theFound, theSortOrder)
+		theFoundLock.Unlock()
 		u.foundList.UnselectAll()
 		u.foundList.Refresh()
 	})
+	sortSelect.Selected = theSortOrder.String() // don't use SetSelected, which would call back before foundList exists
+
+	fuzzyCheck := widget.NewCheck("Fuzzy", func(b bool) {
+		theFuzzyMode = b
+		u.searchEntry.OnChanged(u.searchEntry.Text)
+	})
 
 	u.foundList = widget.NewList(
 		func() int {
+			theFoundLock.Lock()
+			defer theFoundLock.Unlock()
 			return len(theFound)
 		},
 		func() fyne.CanvasObject {
-			return widget.NewLabel("")
+			return newFoundItem()
 		},
 		func(id widget.ListItemID, obj fyne.CanvasObject) {
-			if theFound[id].Date.Year() == 1 {
-				// "no date" shows as Mon 1 Jan 0001
-				obj.(*widget.Label).SetText(theFound[id].Pathname)
-			} else {
-				obj.(*widget.Label).SetText(theFound[id].Date.Format("Mon 2 Jan 2006"))
+			theFoundLock.Lock()
+			defer theFoundLock.Unlock()
+			if id < len(theFound) {
+				obj.(*foundItem).update(theFound[id])
 			}
 		},
 	)
+	u.foundSelected = -1
 	u.foundList.OnSelected = func(id widget.ListItemID) {
-		theNote.SaveIfDirty(theUI.noteEntry.Text)
-		theUI.setCurrentNote(theFound[id])
+		theFoundLock.Lock()
+		if id >= len(theFound) {
+			theFoundLock.Unlock()
+			return
+		}
+		n := theFound[id] // saving may rerun the search and replace theFound
+		theFoundLock.Unlock()
+		if n.Journal != theJournalDir {
+			theUI.switchJournal(n.Journal)
+		} else {
+			theUI.saveNote()
+		}
+		u.foundSelected = id
+		addToHistory(theSearch.Query)
+		theUI.setCurrentNote(n)
+		theUI.jumpToHit(1)
+	}
+	u.foundList.OnUnselected = func(id widget.ListItemID) {
+		u.foundSelected = -1
 	}
 
-	u.noteEntry = widget.NewMultiLineEntry()
+	u.noteEntry = fynex.NewNoteEntry()
 	u.noteEntry.TextStyle = fyne.TextStyle{Monospace: true}
-	u.noteEntry.Wrapping = fyne.TextWrapWord
+	u.noteEntry.TagCompletions = tagCompletions
+	u.noteEntry.TagRune = func(r rune) bool { return theGrammar.IsTagRune(r) }
+	u.noteEntry.Follow = u.followCaret
+	u.noteEntry.MenuItems = u.caretMenuItems
+	ctrlReturn := &desktop.CustomShortcut{KeyName: fyne.KeyReturn, Modifier: fyne.KeyModifierControl}
+	u.noteEntry.AddShortcut(ctrlReturn, func(shortcut fyne.Shortcut) {
+		u.followCaret()
+	})
+	ctrlF := &desktop.CustomShortcut{KeyName: fyne.KeyF, Modifier: fyne.KeyModifierControl}
+	u.noteEntry.AddShortcut(ctrlF, func(shortcut fyne.Shortcut) {
+		u.showFindBar()
+	})
 
 	// https://developer.fyne.io/explore/layouts
 
-	searchForm := container.New(layout.NewBorderLayout(nil, nil, nil, searchEntryClear), searchEntryClear, u.searchEntry)
-	sideTop := container.New(layout.NewVBoxLayout(), u.calendar, searchForm)
-	sideBottom := container.New(layout.NewMaxLayout(), u.foundList)
+	searchButtons := container.New(layout.NewHBoxLayout(), searchDates, searchEntryClear)
+	searchForm := container.New(layout.NewBorderLayout(nil, nil, nil, searchButtons), searchButtons, u.searchEntry)
+	saveSearch := widget.NewButtonWithIcon("", theme.DocumentSaveIcon(), func() {
+		u.promptUserToSaveSearch()
+	})
+	allCheck := widget.NewCheck("All journals", func(b bool) {
+		theAllJournals = b
+		u.searchEntry.OnChanged(u.searchEntry.Text)
+	})
+	searchChecks := container.New(layout.NewHBoxLayout(), fuzzyCheck, allCheck)
+	exportFound := widget.NewButtonWithIcon("", theme.UploadIcon(), func() {
+		u.exportFound()
+	})
+	showPassages := widget.NewButtonWithIcon("", theme.DocumentIcon(), func() {
+		u.showPassages()
+	})
+	sortButtons := container.New(layout.NewHBoxLayout(), saveSearch, showPassages, exportFound)
+	searchSort := container.New(layout.NewBorderLayout(nil, nil, nil, sortButtons), sortButtons, sortSelect)
+	u.foundCount = widget.NewLabel("")
+	u.searchBusy = widget.NewProgressBarInfinite()
+	u.searchBusy.Stop()
+	u.searchBusy.Hide()
+	searchStatus := container.New(layout.NewBorderLayout(nil, nil, u.foundCount, nil), u.foundCount, u.searchBusy)
+	searchOptions := container.New(layout.NewVBoxLayout(), searchChecks, searchSort, searchStatus)
+	sideTop := container.New(layout.NewVBoxLayout(), u.calendar, searchForm, searchOptions)
+	u.savedList = u.buildSavedList()
+	u.breadcrumbs = container.New(layout.NewVBoxLayout())
+	u.breadcrumbs.Hide()
+	u.sideTabs = container.NewAppTabs(
+		container.NewTabItem("Found", container.New(layout.NewBorderLayout(u.breadcrumbs, nil, nil, nil), u.breadcrumbs, u.foundList)),
+		container.NewTabItem("Saved", u.savedList),
+		container.NewTabItem("Tasks", u.buildTaskPanel()),
+	)
+	sideBottom := container.New(layout.NewMaxLayout(), u.sideTabs)
 	side := container.New(layout.NewBorderLayout(sideTop, nil, nil, nil), sideTop, sideBottom)
 
-	mainPanel := container.New(layout.NewBorderLayout(u.toolbar, nil, nil, nil), u.toolbar, u.noteEntry)
+	mainTop := container.New(layout.NewVBoxLayout(), u.toolbar, u.buildFindBar(), u.buildDueBanner())
+	tagPanel := u.buildTagPanel()
+	u.noteTint = canvas.NewRectangle(color.Transparent)
+	editor := container.New(layout.NewMaxLayout(), u.noteTint, u.noteEntry)
+	u.mainPanel = container.New(layout.NewBorderLayout(mainTop, nil, nil, tagPanel), mainTop, tagPanel, editor)
+	u.dockTagPanel()
 
-	// u.noteEntry.OnChanged = func(str string) { println(str) }
-	return fynex.NewAdaptiveSplit(side, mainPanel)
+	u.noteEntry.OnChanged = func(str string) {
+		noteEdited(theNote.Pathname, str)
+	}
+	return fynex.NewAdaptiveSplit(side, u.mainPanel)
 }
 func main() {
 	{
@@ -468,16 +577,25 @@ func main() {
 
 	theUI = &ui{mainWindow: a.NewWindow(appTitle()), theme: fynex.NewNoteTheme()}
 	a.Settings().SetTheme(theUI.theme)
-	theNote = note.NewNote(theDirectory, time.Now())
+	theNote = note.NewNote(theJournalDir, theDirectory, time.Now())
 
 	// shortcuts get swallowed if focus is in the note multiline entry widget
 	// don't need this: just tap the 'today' icon in the taskbar
 	ctrlS := &desktop.CustomShortcut{KeyName: fyne.KeyS, Modifier: fyne.KeyModifierControl}
 	theUI.mainWindow.Canvas().AddShortcut(ctrlS, func(shortcut fyne.Shortcut) {
-		theNote.SaveIfDirty(theUI.noteEntry.Text)
+		theUI.saveNote()
+	})
+	ctrlF := &desktop.CustomShortcut{KeyName: fyne.KeyF, Modifier: fyne.KeyModifierControl}
+	theUI.mainWindow.Canvas().AddShortcut(ctrlF, func(shortcut fyne.Shortcut) {
+		theUI.showFindBar()
 	})
 
+	loadSettings()
+	indexJournal()
 	theUI.mainWindow.SetContent(buildUI(theUI))
+	theUI.refreshSavedSearches()
+	theUI.refreshTasks()
+	scheduleReminders()
 	theUI.mainWindow.Canvas().Focus(theUI.noteEntry)
 	theUI.displayText()
 
@@ -486,5 +604,7 @@ func main() {
 	theUI.mainWindow.ShowAndRun()
 
 	// we *do* come here when app quits because window close [x] button pressed
-	theNote.SaveIfDirty(theUI.noteEntry.Text)
+	if _, err := theNote.SaveIfDirty(theUI.noteEntry.Text); err != nil {
+		log.Println(err)
+	}
 }
@@ -0,0 +1,35 @@
+package note
+
+// Indexer keeps something about each note of a journal
+type Indexer interface {
+	Index(n *Note) // n has been loaded; its Text is empty if it has been removed
+}
+
+// Index reads every note of a journal once for all its indexers, and keeps them up to date as notes change
+type Index struct {
+	journal   string
+	directory string
+	indexers  []Indexer
+}
+
+// NewIndex passes every note of the journal kept in directory to the indexers
+func NewIndex(journal, directory string, indexers ...Indexer) *Index {
+	x := &Index{journal: journal, directory: directory, indexers: indexers}
+	Walk(journal, directory, func(n *Note) error {
+		x.index(n)
+		return nil
+	})
+	return x
+}
+
+func (x *Index) index(n *Note) {
+	n.Load()
+	for _, i := range x.indexers {
+		i.Index(n)
+	}
+}
+
+// Update passes a note, which may have been removed, to the indexers after it has been changed
+func (x *Index) Update(pathname string) {
+	x.index(NewNote(x.journal, x.directory, pathname))
+}
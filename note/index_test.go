@@ -0,0 +1,41 @@
+package note
+
+import (
+	"os"
+	"testing"
+	"time"
+)
+
+// texts is an Indexer that remembers the text of each note it is given
+type texts map[string]string
+
+func (ts texts) Index(n *Note) {
+	ts[n.Pathname] = n.Text
+}
+
+func TestIndex(t *testing.T) {
+	directory := t.TempDir()
+	n := NewNote("Default", directory, time.Date(2023, time.July, 4, 0, 0, 0, 0, time.Local))
+	if _, err := n.SaveIfDirty("first\n"); err != nil {
+		t.Fatal(err)
+	}
+	a, b := texts{}, texts{}
+	x := NewIndex("Default", directory, a, b)
+	for _, ts := range []texts{a, b} {
+		if len(ts) != 1 || ts[n.Pathname] != "first\n" {
+			t.Fatalf("indexed %q", ts)
+		}
+	}
+	if err := os.WriteFile(n.Pathname, []byte("second\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	x.Update(n.Pathname)
+	if a[n.Pathname] != "second\n" || b[n.Pathname] != "second\n" {
+		t.Errorf("after update, indexed %q and %q", a, b)
+	}
+	n.Remove()
+	x.Update(n.Pathname)
+	if a[n.Pathname] != "" {
+		t.Errorf("after removal, indexed %q", a)
+	}
+}
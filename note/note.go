@@ -2,7 +2,7 @@ package note
 
 import (
 	"fmt"
-	"log"
+	"io/fs"
 	"os"
 	"path"
 	"path/filepath"
@@ -17,10 +17,54 @@ type Note struct {
 	Text     string
 	Pathname string
 	Date     time.Time
+	Journal  string  // name of the journal the note belongs to, eg Default
+	Hits     []Hit   // lines that matched the last search, if any
+	Score    float64 // relevance of the note to the last search
 }
 
-func NewNote(directory string, obj any) *Note {
-	n := &Note{}
+// Hit is a line in a note that matched a search
+type Hit struct {
+	Line    int     // line number, starting at 1
+	Text    string  // text of the line
+	Matches []Match // where in Text the search matched
+}
+
+// Match is the position of some matching text within a line
+type Match struct {
+	Start, End int  // byte offsets within the line
+	Fuzzy      bool // true if the text is a near miss rather than an exact match
+}
+
+// Exact reports whether any of the note's hits are exact matches
+func (n *Note) Exact() bool {
+	for _, h := range n.Hits {
+		for _, m := range h.Matches {
+			if !m.Fuzzy {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Variants returns the distinct words that fuzzily matched the search, in the order they were found
+func (n *Note) Variants() []string {
+	var variants []string
+	seen := make(map[string]bool)
+	for _, h := range n.Hits {
+		for _, m := range h.Matches {
+			if v := h.Text[m.Start:m.End]; m.Fuzzy && !seen[v] {
+				seen[v] = true
+				variants = append(variants, v)
+			}
+		}
+	}
+	return variants
+}
+
+// NewNote returns the note of the journal kept in directory with either a pathname or a date
+func NewNote(journal, directory string, obj any) *Note {
+	n := &Note{Journal: journal}
 	switch v := obj.(type) {
 	case string:
 		n.Pathname = v
@@ -45,42 +89,100 @@ func NewNote(directory string, obj any) *Note {
 	return n
 }
 
+// HitCount returns the total number of matches in all the hit lines
+func (n *Note) HitCount() int {
+	var count int
+	for _, h := range n.Hits {
+		count += len(h.Matches)
+	}
+	return count
+}
+
+// Walk calls fn with a new note for each .txt file under directory, which holds the named journal,
+// skipping hidden files and directories; if fn returns an error, the walk stops and returns it
+func Walk(journal, directory string, fn func(*Note) error) error {
+	return filepath.WalkDir(directory, func(pathname string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil // ignore unreadable files and directories
+		}
+		if strings.HasPrefix(d.Name(), ".") && pathname != directory {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.IsDir() && filepath.Ext(pathname) == ".txt" {
+			return fn(NewNote(journal, directory, pathname))
+		}
+		return nil
+	})
+}
+
+// Stored returns the text of the note as it is on disk, leaving its Text alone, as it may be the note being edited
+func (n *Note) Stored() string {
+	bytes, _ := os.ReadFile(n.Pathname) // it's ok if pathname does not exist
+	return string(bytes)
+}
+
 func (n *Note) Load() {
 	bytes, _ := os.ReadFile(n.Pathname) // ignore error return because it's ok if pathname does not exist
 	n.Text = string(bytes)
 }
 
-func (n *Note) Save() {
-	var err error
+// Save writes the note to a temporary file and then renames it into place,
+// so a note is never left half written if saving fails
+func (n *Note) Save() error {
 	// make sure the data dir has been created
 	dir, _ := filepath.Split(n.Pathname)
 	// https://stackoverflow.com/questions/14249467/os-mkdir-and-os-mkdirall-permission-value
-	if err = os.MkdirAll(dir, 0755); err != nil {
-		log.Fatal(err)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
 	}
 	// if path is already a directory, MkdirAll does nothing and returns nil
 
-	var file *os.File
-	if file, err = os.Create(n.Pathname); err != nil {
-		log.Fatal(err)
+	// hidden, so Walk skips it if it is left behind
+	file, err := os.CreateTemp(dir, "."+filepath.Base(n.Pathname)+".*")
+	if err != nil {
+		return err
 	}
+	defer os.Remove(file.Name()) // fails harmlessly once the file has been renamed
 	if _, err = file.Write([]byte(n.Text)); err != nil {
-		log.Fatal(err)
+		file.Close()
+		return err
+	}
+	if err = file.Chmod(0644); err != nil {
+		file.Close()
+		return err
 	}
 	if err = file.Close(); err != nil {
-		log.Fatal(err)
+		return err
 	}
+	return os.Rename(file.Name(), n.Pathname)
 }
 
-func (n *Note) SaveIfDirty(newText string) {
-	if newText != n.Text {
-		if util.IsStringEmpty(newText) {
-			n.Remove()
-		} else {
-			n.Text = newText
-			n.Save()
-		}
+// SaveIfDirty saves the note if newText differs from its text, or removes it if newText is empty,
+// reporting whether anything was written; the note keeps its old text if saving fails
+func (n *Note) SaveIfDirty(newText string) (bool, error) {
+	if newText == n.Text {
+		return false, nil
+	}
+	if util.IsStringEmpty(newText) {
+		n.Remove()
+		return true, nil
 	}
+	old := n.Text
+	n.Text = newText
+	if err := n.Save(); err != nil {
+		n.Text = old
+		return false, err
+	}
+	return true, nil
+}
+
+// Exists reports whether the note has been written, as notes are removed when they are emptied
+func (n *Note) Exists() bool {
+	_, err := os.Stat(n.Pathname)
+	return err == nil
 }
 
 func (n *Note) Remove() {
@@ -0,0 +1,47 @@
+package note
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSave(t *testing.T) {
+	directory := t.TempDir()
+	n := NewNote("Default", directory, time.Date(2023, time.July, 4, 0, 0, 0, 0, time.Local))
+	for _, text := range []string{"first\n", "second, shorter"} {
+		if changed, err := n.SaveIfDirty(text); err != nil || !changed {
+			t.Fatalf("SaveIfDirty(%q) = %v, %v", text, changed, err)
+		}
+		if got := n.Stored(); got != text {
+			t.Errorf("saved %q, want %q", got, text)
+		}
+	}
+	entries, _ := os.ReadDir(filepath.Dir(n.Pathname))
+	if len(entries) != 1 {
+		t.Errorf("saving left %d files behind", len(entries)-1)
+	}
+	if changed, _ := n.SaveIfDirty("second, shorter"); changed {
+		t.Error("SaveIfDirty saved an unchanged note")
+	}
+	if changed, err := n.SaveIfDirty(" \n"); err != nil || !changed || n.Exists() {
+		t.Errorf("SaveIfDirty of an empty note = %v, %v, and it exists %v", changed, err, n.Exists())
+	}
+}
+
+func TestSaveFails(t *testing.T) {
+	directory := t.TempDir()
+	// the year is a file, so the note can't be written under it
+	if err := os.WriteFile(filepath.Join(directory, "2023"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	n := NewNote("Default", directory, time.Date(2023, time.July, 4, 0, 0, 0, 0, time.Local))
+	n.Text = "old"
+	if changed, err := n.SaveIfDirty("new"); err == nil || changed {
+		t.Errorf("SaveIfDirty = %v, %v, want an error", changed, err)
+	}
+	if n.Text != "old" {
+		t.Errorf("the note's text is %q after failing to save, want it unchanged", n.Text)
+	}
+}
@@ -0,0 +1,54 @@
+package note
+
+import "strings"
+
+// Paragraph is a run of lines in a note that are not blank
+type Paragraph struct {
+	First, Last int // line numbers, starting at 1
+	Text        string
+}
+
+// Paragraphs splits text into paragraphs at blank lines
+func Paragraphs(text string) []Paragraph {
+	var paras []Paragraph
+	var lines []string
+	first := 0
+	flush := func(last int) {
+		if len(lines) > 0 {
+			paras = append(paras, Paragraph{First: first, Last: last, Text: strings.Join(lines, "\n")})
+			lines = nil
+		}
+	}
+	all := strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+	for i, line := range all {
+		if strings.TrimSpace(line) == "" {
+			flush(i)
+			continue
+		}
+		if len(lines) == 0 {
+			first = i + 1
+		}
+		lines = append(lines, line)
+	}
+	flush(len(all))
+	return paras
+}
+
+// HitParagraphs returns the paragraphs of text containing any of the hits
+func HitParagraphs(text string, hits []Hit) []Paragraph {
+	var paras []Paragraph
+	for _, p := range Paragraphs(text) {
+		for _, h := range hits {
+			if p.Contains(h.Line) {
+				paras = append(paras, p)
+				break
+			}
+		}
+	}
+	return paras
+}
+
+// Contains reports whether line (counting from 1) is in the paragraph
+func (p Paragraph) Contains(line int) bool {
+	return line >= p.First && line <= p.Last
+}
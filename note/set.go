@@ -0,0 +1,80 @@
+package note
+
+import "path/filepath"
+
+// ID returns the identity of the note, which is the same for every Note made for the same file
+func (n *Note) ID() string {
+	return n.Journal + ":" + filepath.Clean(n.Pathname)
+}
+
+// NoteSet is a set of notes, which keeps them in the order they were added
+type NoteSet struct {
+	notes []*Note
+	index map[string]struct{} // IDs of the notes
+}
+
+// NewNoteSet returns a set of the notes, ignoring any duplicates
+func NewNoteSet(notes []*Note) *NoteSet {
+	s := &NoteSet{index: make(map[string]struct{}, len(notes))}
+	for _, n := range notes {
+		s.Add(n)
+	}
+	return s
+}
+
+// Add adds a note to the set, returning false if it was already there
+func (s *NoteSet) Add(n *Note) bool {
+	id := n.ID()
+	if _, ok := s.index[id]; ok {
+		return false
+	}
+	s.index[id] = struct{}{}
+	s.notes = append(s.notes, n)
+	return true
+}
+
+// Contains reports whether the set has a note for the same file as n
+func (s *NoteSet) Contains(n *Note) bool {
+	_, ok := s.index[n.ID()]
+	return ok
+}
+
+func (s *NoteSet) Len() int {
+	return len(s.notes)
+}
+
+// Notes returns the notes in the order they were added
+func (s *NoteSet) Notes() []*Note {
+	return append([]*Note{}, s.notes...)
+}
+
+// Union returns the notes in s followed by those in t but not in s
+func (s *NoteSet) Union(t *NoteSet) *NoteSet {
+	u := NewNoteSet(s.notes)
+	for _, n := range t.notes {
+		u.Add(n)
+	}
+	return u
+}
+
+// Intersection returns the notes in s that are also in t
+func (s *NoteSet) Intersection(t *NoteSet) *NoteSet {
+	u := NewNoteSet(nil)
+	for _, n := range s.notes {
+		if t.Contains(n) {
+			u.Add(n)
+		}
+	}
+	return u
+}
+
+// Difference returns the notes in s that are not in t
+func (s *NoteSet) Difference(t *NoteSet) *NoteSet {
+	u := NewNoteSet(nil)
+	for _, n := range s.notes {
+		if !t.Contains(n) {
+			u.Add(n)
+		}
+	}
+	return u
+}
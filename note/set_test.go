@@ -0,0 +1,62 @@
+package note
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNoteSet(t *testing.T) {
+	day := func(d int) *Note {
+		return NewNote("Default", "/j/Default", time.Date(2023, time.July, d, 0, 0, 0, 0, time.Local))
+	}
+	days := func(s *NoteSet) []int {
+		var ds []int
+		for _, n := range s.Notes() {
+			ds = append(ds, n.Date.Day())
+		}
+		return ds
+	}
+	a := NewNoteSet([]*Note{day(3), day(1), day(2), day(1)}) // a different Note for the same file is a duplicate
+	b := NewNoteSet([]*Note{day(4), day(2), day(3)})
+	empty := NewNoteSet(nil)
+	tests := []struct {
+		name string
+		got  *NoteSet
+		want []int
+	}{
+		{"a", a, []int{3, 1, 2}},
+		{"a union b", a.Union(b), []int{3, 1, 2, 4}},
+		{"b union a", b.Union(a), []int{4, 2, 3, 1}},
+		{"a intersection b", a.Intersection(b), []int{3, 2}},
+		{"a difference b", a.Difference(b), []int{1}},
+		{"b difference a", b.Difference(a), []int{4}},
+		{"a union empty", a.Union(empty), []int{3, 1, 2}},
+		{"a intersection empty", a.Intersection(empty), nil},
+		{"a difference empty", a.Difference(empty), []int{3, 1, 2}},
+		{"empty difference a", empty.Difference(a), nil},
+	}
+	for _, tt := range tests {
+		got := days(tt.got)
+		if len(got) != len(tt.want) || tt.got.Len() != len(tt.want) {
+			t.Errorf("%s = %v, want %v", tt.name, got, tt.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Errorf("%s = %v, want %v", tt.name, got, tt.want)
+				break
+			}
+		}
+	}
+	if a.Len() != 3 {
+		t.Errorf("combining changed a, which has %d notes", a.Len())
+	}
+	if !a.Contains(day(2)) || a.Contains(day(4)) {
+		t.Error("Contains is wrong")
+	}
+	// the same day in another journal is another note
+	other := NewNote("Work", "/j/Work", time.Date(2023, time.July, 1, 0, 0, 0, 0, time.Local))
+	if a.Contains(other) {
+		t.Error("a note in another journal counts as the same note")
+	}
+}
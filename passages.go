@@ -0,0 +1,81 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/widget"
+	"oddstream.cj/note"
+	"oddstream.cj/search"
+)
+
+// passage is the part of a found note that is relevant to the search
+type passage struct {
+	n    *note.Note
+	para note.Paragraph
+}
+
+// findPassages returns the passages of the found notes that matter to the query, in order (see search.Passages)
+func findPassages(found []*note.Note, query string) []passage {
+	q, _ := search.ParseQuery(query, time.Now()) // a query that doesn't parse finds nothing anyway
+	q.Grammar = theGrammar
+	var passages []passage
+	for _, n := range found {
+		for _, p := range search.Passages(n, q) {
+			passages = append(passages, passage{n: n, para: p})
+		}
+	}
+	return passages
+}
+
+// showPassages pops up the passages of the found notes stitched together, to be read in sequence;
+// tapping the heading of a passage opens its note there
+func (u *ui) showPassages() {
+	var pu *widget.PopUp
+
+	theFoundLock.Lock()
+	found := append([]*note.Note{}, theFound...)
+	theFoundLock.Unlock()
+	if len(found) == 0 {
+		return
+	}
+	u.saveNote()
+	passages := findPassages(found, theSearch.Query)
+
+	box := container.New(layout.NewVBoxLayout())
+	for _, p := range passages {
+		p := p
+		title := noteTitle(p.n)
+		if theSearch.AllJournals {
+			title += " (" + p.n.Journal + ")"
+		}
+		heading := widget.NewButton(title, func() {
+			pu.Hide()
+			if p.n.Journal != theJournalDir {
+				u.switchJournal(p.n.Journal)
+			} else {
+				u.saveNote()
+			}
+			u.setCurrentNote(p.n)
+			u.jumpToHit(p.para.First)
+		})
+		heading.Importance = widget.LowImportance
+		heading.Alignment = widget.ButtonAlignLeading
+		text := widget.NewLabel(p.para.Text)
+		text.Wrapping = fyne.TextWrapWord
+		text.TextStyle = fyne.TextStyle{Monospace: true}
+		box.Add(heading)
+		box.Add(text)
+	}
+	hdr := widget.NewLabel(fmt.Sprintf("%d passages from %d notes", len(passages), len(found)))
+	closeButton := widget.NewButton("Close", func() {
+		pu.Hide()
+	})
+	content := container.New(layout.NewBorderLayout(hdr, closeButton, nil, nil), hdr, closeButton, container.NewVScroll(box))
+	pu = widget.NewModalPopUp(content, u.mainWindow.Canvas())
+	pu.Resize(u.mainWindow.Canvas().Size().Subtract(fyne.NewSize(80, 80)))
+	pu.Show()
+}
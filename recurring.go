@@ -0,0 +1,98 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/widget"
+	"oddstream.cj/tasks"
+	"oddstream.cj/util"
+)
+
+// habitOccurrences is how many of the latest occurrences of a recurring task the habit grid shows
+const habitOccurrences = 28
+
+// addRecurring adds the tasks that recur on the day of the note being edited to it, if they aren't in it yet.
+// Each task is only added to a day's note once, so one that has been deleted to skip it stays deleted.
+func (u *ui) addRecurring() {
+	if theNote.Date.Year() == 1 || theTaskCatalogue == nil {
+		return
+	}
+	day := util.DayOf(theNote.Date)
+	date := day.Format("2006-01-02")
+	var recurring []tasks.Series
+	for _, s := range theTaskCatalogue.Recurring(day) {
+		if !util.Contains(theSettings.Recurred[date], s.Text) {
+			recurring = append(recurring, s)
+		}
+	}
+	if len(recurring) == 0 {
+		return
+	}
+	if theSettings.Recurred == nil {
+		theSettings.Recurred = make(map[string][]string)
+	}
+	text := u.noteEntry.Text
+	if text != "" && !strings.HasSuffix(text, "\n") {
+		text += "\n"
+	}
+	for _, s := range recurring {
+		text += "[ ] " + s.Text + "\n"
+		theSettings.Recurred[date] = append(theSettings.Recurred[date], s.Text)
+	}
+	saveSettings()
+	u.noteEntry.SetText(text) // saved like any other edit
+}
+
+// habitGrid draws the latest occurrences of a recurring task up to today, oldest first,
+// as ■ if it was done, □ if it wasn't, and · for today's if it is still to do
+func habitGrid(s tasks.Series) string {
+	day := today()
+	occs := s.Occurrences(day)
+	if len(occs) > habitOccurrences {
+		occs = occs[len(occs)-habitOccurrences:]
+	}
+	var b strings.Builder
+	for _, d := range occs {
+		switch {
+		case s.Done[d]:
+			b.WriteRune('■')
+		case d.Equal(day):
+			b.WriteRune('·')
+		default:
+			b.WriteRune('□')
+		}
+	}
+	return b.String()
+}
+
+// showHabits pops up a grid of how often each recurring task has been done, with its streaks
+func (u *ui) showHabits() {
+	var pu *widget.PopUp
+
+	u.saveNote()
+	box := container.New(layout.NewVBoxLayout())
+	for _, s := range theTaskCatalogue.Series() {
+		name := widget.NewLabel(s.Text)
+		name.TextStyle = fyne.TextStyle{Bold: true}
+		name.Wrapping = fyne.TextWrapWord
+		current, best := s.Streaks(today())
+		grid := widget.NewLabel(fmt.Sprintf("%s\nstreak %d, best %d", habitGrid(s), current, best))
+		grid.TextStyle = fyne.TextStyle{Monospace: true}
+		box.Add(name)
+		box.Add(grid)
+	}
+	if len(box.Objects) == 0 {
+		box.Add(widget.NewLabel("There are no recurring tasks, like [ ] exercise @every(day)"))
+	}
+	closed := widget.NewButton("Close", func() {
+		pu.Hide()
+	})
+	content := container.New(layout.NewBorderLayout(nil, closed, nil, nil), closed, container.NewVScroll(box))
+	pu = widget.NewModalPopUp(content, u.mainWindow.Canvas())
+	pu.Resize(fyne.NewSize(480, 480))
+	pu.Show()
+}
@@ -0,0 +1,159 @@
+package main
+
+import (
+	"encoding/json"
+	"log"
+	"os"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"oddstream.cj/note"
+	"oddstream.cj/util"
+)
+
+// remindedFilename is the name of the file, in the journal's directory, holding the reminders that have gone off,
+// so they don't go off again when cj is restarted; it is hidden so that searches skip it
+const remindedFilename = ".reminded.json"
+
+// a reminder is only remembered as having gone off until the day after, as it can't go off again after that
+const remindedDays = 2
+
+var (
+	theRemindLock   sync.Mutex      // guards these, which the timers use from their own goroutines
+	theRemindTimers []*time.Timer   // one for each reminder still to go off, and one for the nudge
+	theRemindSerial int             // incremented whenever the timers are replaced, so a timer already going off knows it is out of date
+	theReminded     map[string]bool // keys of the reminders that have gone off in theRemindedDir
+	theRemindedDir  string          // the journal directory theReminded belongs to
+	theEditing      string          // the pathname of the note being edited
+	theEditingEmpty bool            // whether nothing has been written in the editor
+)
+
+// scheduleReminders sets a timer for each reminder in the journal that hasn't gone off yet, from today on,
+// replacing any timers already set; reminders from earlier today, missed because cj wasn't running, go off at once
+func scheduleReminders() {
+	theRemindLock.Lock()
+	defer theRemindLock.Unlock()
+	for _, t := range theRemindTimers {
+		t.Stop()
+	}
+	theRemindTimers = nil
+	theRemindSerial++
+	if theRemindedDir != theDirectory {
+		theRemindedDir = theDirectory
+		theReminded = loadReminded(theRemindedDir)
+	}
+	serial := theRemindSerial
+	for _, r := range theTaskCatalogue.Reminders(today()) {
+		if theReminded[r.Key()] {
+			continue
+		}
+		r := r
+		theRemindTimers = append(theRemindTimers, time.AfterFunc(time.Until(r.At), func() {
+			theRemindLock.Lock()
+			defer theRemindLock.Unlock()
+			if serial == theRemindSerial {
+				remind(r.Key(), r.At.Format("15:04"), r.Text)
+			}
+		}))
+	}
+	if nudge, err := time.Parse("15:04", theSettings.NudgeTime); err == nil {
+		scheduleNudge(serial, theDirectory, theJournalDir, nudge)
+	} else if theSettings.NudgeTime != "" {
+		log.Printf("nudgeTime: %q is not a time like 20:00\n", theSettings.NudgeTime)
+	}
+}
+
+// scheduleNudge sets a timer to nudge the user at a time of day if nothing has been written today
+// in the journal in directory; once it has gone off, it sets itself again for the next day.
+// The lock must be held.
+func scheduleNudge(serial int, directory string, journal string, clock time.Time) {
+	day := today()
+	at := time.Date(day.Year(), day.Month(), day.Day(), clock.Hour(), clock.Minute(), 0, 0, time.Local)
+	if theReminded[nudgeKey(day)] {
+		at = at.AddDate(0, 0, 1)
+	}
+	theRemindTimers = append(theRemindTimers, time.AfterFunc(time.Until(at), func() {
+		n := note.NewNote(journal, directory, at)
+		theRemindLock.Lock()
+		defer theRemindLock.Unlock()
+		if serial != theRemindSerial {
+			return // the journal, or its reminders, have changed since
+		}
+		key := nudgeKey(util.DayOf(at))
+		if isEmpty(n) {
+			remind(key, "Nothing written today", "Write today's entry in "+journal)
+		}
+		theReminded[key] = true // whether it went off or not, it is done for the day
+		saveReminded(directory, theReminded)
+		scheduleNudge(serial, directory, journal, clock)
+	}))
+}
+
+func nudgeKey(day time.Time) string {
+	return day.Format("2006-01-02T15:04") + " nudge"
+}
+
+// isEmpty reports whether nothing has been written in a note, either on disk or in the editor.
+// The lock must be held.
+func isEmpty(n *note.Note) bool {
+	if theEditing == n.Pathname && !theEditingEmpty {
+		return false
+	}
+	return strings.TrimSpace(n.Stored()) == ""
+}
+
+// noteEdited keeps track of whether anything has been written in the editor, for the nudge
+func noteEdited(pathname string, text string) {
+	theRemindLock.Lock()
+	defer theRemindLock.Unlock()
+	theEditing = pathname
+	theEditingEmpty = strings.TrimSpace(text) == ""
+}
+
+// remind pops up a desktop notification, unless the reminder with the key has gone off already.
+// The lock must be held.
+func remind(key string, title string, content string) {
+	if theReminded[key] {
+		return
+	}
+	theReminded[key] = true
+	saveReminded(theRemindedDir, theReminded)
+	fyne.CurrentApp().SendNotification(fyne.NewNotification(title, content))
+}
+
+func loadReminded(directory string) map[string]bool {
+	reminded := make(map[string]bool)
+	bytes, err := os.ReadFile(path.Join(directory, remindedFilename))
+	if err != nil {
+		return reminded // it's ok if no reminders have gone off yet
+	}
+	var keys []string
+	if err := json.Unmarshal(bytes, &keys); err != nil {
+		log.Printf("couldn't read reminders for %s: %s\n", directory, err)
+	}
+	for _, key := range keys {
+		reminded[key] = true
+	}
+	return reminded
+}
+
+// saveReminded saves the keys of the reminders that have gone off, forgetting those too old to go off again
+func saveReminded(directory string, reminded map[string]bool) {
+	oldest := today().AddDate(0, 0, 1-remindedDays).Format("2006-01-02")
+	keys := []string{}
+	for key := range reminded {
+		if len(key) >= len(oldest) && key[:len(oldest)] >= oldest {
+			keys = append(keys, key)
+		}
+	}
+	bytes, err := json.MarshalIndent(keys, "", "\t")
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := os.WriteFile(path.Join(directory, remindedFilename), bytes, 0644); err != nil {
+		log.Printf("couldn't save reminders for %s: %s\n", directory, err)
+	}
+}
@@ -0,0 +1,229 @@
+package main
+
+import (
+	"fmt"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/widget"
+	"oddstream.cj/note"
+	"oddstream.cj/search"
+)
+
+// replaced remembers the text of a note before and after a find and replace, so it can be undone
+type replaced struct {
+	pathname string
+	before   string
+	after    string
+}
+
+var theLastReplace []replaced // the notes changed by the last find and replace
+
+// findAndReplace pops up a find and replace for the whole journal,
+// listing every occurrence so each can be included or left alone
+func (u *ui) findAndReplace() {
+	var pu *widget.PopUp
+	var occs []search.Occurrence
+	var included []bool
+
+	u.saveNote() // so the current note is searched as it is in the editor
+
+	findEntry := widget.NewEntry()
+	findEntry.PlaceHolder = "Find"
+	replaceEntry := widget.NewEntry()
+	replaceEntry.PlaceHolder = "Replace with"
+	matchCase := widget.NewCheck("Match case", nil)
+	summary := widget.NewLabel("")
+
+	var lbox *widget.List
+	showSummary := func() {
+		var count int
+		notes := make(map[string]bool)
+		for i, o := range occs {
+			if included[i] {
+				count++
+				notes[o.Note.Pathname] = true
+			}
+		}
+		summary.SetText(fmt.Sprintf("%d of %d occurrences, in %d notes", count, len(occs), len(notes)))
+	}
+	preview := func() {
+		occs = search.Occurrences(theJournalDir, theDirectory, findEntry.Text, matchCase.Checked)
+		included = make([]bool, len(occs))
+		for i := range included {
+			included[i] = true
+		}
+		lbox.Refresh()
+		showSummary()
+	}
+	findEntry.OnSubmitted = func(string) { preview() }
+
+	lbox = widget.NewList(
+		func() int {
+			return len(occs)
+		},
+		func() fyne.CanvasObject {
+			check := widget.NewCheck("", nil)
+			date := widget.NewLabel("")
+			rt := widget.NewRichText()
+			rt.Wrapping = fyne.TextTruncate
+			left := container.New(layout.NewHBoxLayout(), check, date)
+			return container.New(layout.NewBorderLayout(nil, nil, left, nil), left, rt)
+		},
+		func(id widget.ListItemID, obj fyne.CanvasObject) {
+			c := obj.(*fyne.Container)
+			left := c.Objects[0].(*fyne.Container)
+			check := left.Objects[0].(*widget.Check)
+			check.OnChanged = nil // don't call back while setting up the row
+			check.SetChecked(included[id])
+			check.OnChanged = func(b bool) {
+				included[id] = b
+				showSummary()
+			}
+			o := occs[id]
+			if o.Note.Date.Year() == 1 {
+				left.Objects[1].(*widget.Label).SetText(o.Note.Pathname)
+			} else {
+				left.Objects[1].(*widget.Label).SetText(o.Note.Date.Format("2006-01-02"))
+			}
+			rt := c.Objects[1].(*widget.RichText)
+			rt.Segments = hitSegments([]note.Hit{search.Snippet(o.Hit, 60)})
+			rt.Refresh()
+		},
+	)
+
+	setAll := func(b bool) {
+		for i := range included {
+			included[i] = b
+		}
+		lbox.Refresh()
+		showSummary()
+	}
+	var undo *widget.Button
+	replace := widget.NewButton("Replace", func() {
+		if len(occs) == 0 {
+			return
+		}
+		u.saveNote()
+		batch, err := replaceOccurrences(occs, included, replaceEntry.Text)
+		if len(batch) > 0 {
+			theLastReplace = batch
+			undo.Enable()
+			u.afterReplace(batch)
+		}
+		if err != nil {
+			dialog.ShowError(err, u.mainWindow)
+		}
+		preview()
+	})
+	undo = widget.NewButton("Undo last replace", func() {
+		u.undoReplace()
+		undo.Disable()
+		preview()
+	})
+	if len(theLastReplace) == 0 {
+		undo.Disable()
+	}
+	closed := widget.NewButton("Close", func() {
+		pu.Hide()
+	})
+
+	top := container.New(layout.NewVBoxLayout(),
+		findEntry,
+		replaceEntry,
+		container.New(layout.NewHBoxLayout(), matchCase, widget.NewButton("Preview", preview),
+			widget.NewButton("All", func() { setAll(true) }),
+			widget.NewButton("None", func() { setAll(false) })),
+	)
+	bottom := container.New(layout.NewVBoxLayout(),
+		summary,
+		container.New(layout.NewGridLayout(3), replace, undo, closed),
+	)
+	content := container.New(layout.NewBorderLayout(top, bottom, nil, nil), top, lbox, bottom)
+	pu = widget.NewModalPopUp(content, u.mainWindow.Canvas())
+	pu.Resize(fyne.NewSize(640, 480))
+	pu.Show()
+	pu.Canvas.Focus(findEntry)
+}
+
+// replaceOccurrences writes the included occurrences to disk, a note at a time,
+// returning what was changed so it can be undone
+func replaceOccurrences(occs []search.Occurrence, included []bool, replacement string) ([]replaced, error) {
+	var batch []replaced
+	var firstErr error
+	byNote := make(map[string][]search.Occurrence)
+	var order []*note.Note
+	for i, o := range occs {
+		if !included[i] {
+			continue
+		}
+		if _, ok := byNote[o.Note.Pathname]; !ok {
+			order = append(order, o.Note)
+		}
+		byNote[o.Note.Pathname] = append(byNote[o.Note.Pathname], o)
+	}
+	for _, n := range order {
+		current := note.NewNote(theJournalDir, theDirectory, n.Pathname)
+		current.Load()
+		after, err := search.Replace(current.Text, byNote[n.Pathname], replacement)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		before := current.Text
+		changed, err := current.SaveIfDirty(after)
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+		if changed {
+			batch = append(batch, replaced{pathname: n.Pathname, before: before, after: after})
+		}
+	}
+	return batch, firstErr
+}
+
+// undoReplace puts back the notes changed by the last find and replace,
+// unless they have been edited again since
+func (u *ui) undoReplace() {
+	u.saveNote()
+	var undone []replaced
+	var firstErr error
+	for _, r := range theLastReplace {
+		current := note.NewNote(theJournalDir, theDirectory, r.pathname)
+		current.Load()
+		if current.Text != r.after {
+			continue
+		}
+		changed, err := current.SaveIfDirty(r.before)
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+		if changed {
+			undone = append(undone, r)
+		}
+	}
+	theLastReplace = nil
+	u.afterReplace(undone)
+	if firstErr != nil {
+		dialog.ShowError(firstErr, u.mainWindow)
+	}
+	if len(undone) > 0 {
+		dialog.ShowInformation("Undo", fmt.Sprintf("%d notes put back", len(undone)), u.mainWindow)
+	}
+}
+
+// afterReplace reloads the current note if it was one of those changed
+func (u *ui) afterReplace(batch []replaced) {
+	var pathnames []string
+	for _, r := range batch {
+		if r.pathname == theNote.Pathname {
+			u.reloadNote()
+		}
+		pathnames = append(pathnames, r.pathname)
+	}
+	u.notesChanged(pathnames)
+}
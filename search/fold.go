@@ -0,0 +1,204 @@
+package search
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+	"oddstream.cj/note"
+	"oddstream.cj/tags"
+)
+
+// fold normalizes s for comparison, decomposing it (NFKD), stripping the combining marks
+// and lower-casing it, so that "Café" and "cafe" fold to the same thing.
+// It also returns, for each byte of the folded string, the offset of the rune in s it came from.
+func fold(s string) (string, []int) {
+	var b strings.Builder
+	offsets := make([]int, 0, len(s))
+	for i, r := range s {
+		for _, d := range norm.NFKD.String(string(r)) {
+			if unicode.Is(unicode.Mn, d) {
+				continue
+			}
+			n, _ := b.WriteRune(unicode.ToLower(d))
+			for k := 0; k < n; k++ {
+				offsets = append(offsets, i)
+			}
+		}
+	}
+	return b.String(), offsets
+}
+
+// unfold converts a byte range [start, end) of a folded string back into a range of the original
+func unfold(s string, offsets []int, start, end int) (int, int) {
+	_, size := utf8.DecodeRuneInString(s[offsets[end-1]:])
+	return offsets[start], offsets[end-1] + size
+}
+
+// matcher finds the text of a query in lines of notes
+type matcher struct {
+	phrase  string   // the folded query text
+	words   []string // the folded words of the query, for fuzzy matching
+	fuzzy   bool
+	tag     string // the key of the tag, if the query is just a hashtag
+	grammar tags.Grammar
+}
+
+func newMatcher(q Query) *matcher {
+	m := &matcher{fuzzy: q.Fuzzy, grammar: q.Grammar}
+	if q.Grammar.Valid(q.Text) {
+		// a hashtag only matches the whole tag, so #go doesn't find #goodcar
+		m.tag = tags.Key(q.Text)
+	}
+	m.phrase, _ = fold(q.Text)
+	for _, w := range splitWords(m.phrase) {
+		m.words = append(m.words, m.phrase[w[0]:w[1]])
+	}
+	return m
+}
+
+// match returns the matches in line, setting seen[i] if the ith query word was matched.
+// Exact matches of the whole query text count as matching every word.
+func (m *matcher) match(line string, seen []bool) []note.Match {
+	var matches []note.Match
+	if m.phrase == "" {
+		return matches
+	}
+	if m.tag != "" {
+		return m.matchTag(line, seen)
+	}
+	folded, offsets := fold(line)
+	covered := make([]bool, len(folded)) // folded bytes already inside an exact match
+	for from := 0; from < len(folded); {
+		i := strings.Index(folded[from:], m.phrase)
+		if i < 0 {
+			break
+		}
+		start, end := from+i, from+i+len(m.phrase)
+		for k := start; k < end; k++ {
+			covered[k] = true
+		}
+		s, e := unfold(line, offsets, start, end)
+		matches = append(matches, note.Match{Start: s, End: e})
+		from = end
+	}
+	if len(matches) > 0 {
+		for i := range seen {
+			seen[i] = true
+		}
+	}
+	if !m.fuzzy {
+		return matches
+	}
+	for _, w := range splitWords(folded) {
+		if covered[w[0]] {
+			continue
+		}
+		word := folded[w[0]:w[1]]
+		for i, qw := range m.words {
+			// words of the query found on their own count too, so the words needn't be together
+			if exact := word == qw; exact || distance(word, qw) <= maxEdits(qw) {
+				s, e := unfold(line, offsets, w[0], w[1])
+				matches = append(matches, note.Match{Start: s, End: e, Fuzzy: !exact})
+				seen[i] = true
+				break
+			}
+		}
+	}
+	sortMatches(matches)
+	return matches
+}
+
+// matchTag returns the uses of the query's tag in line, written in any case
+func (m *matcher) matchTag(line string, seen []bool) []note.Match {
+	var matches []note.Match
+	for _, t := range m.grammar.Tokenize(line) {
+		if t.Key == m.tag {
+			matches = append(matches, note.Match{Start: t.Start, End: t.End})
+		}
+	}
+	if len(matches) > 0 {
+		for i := range seen {
+			seen[i] = true
+		}
+	}
+	return matches
+}
+
+// maxEdits is how many typos a word can have and still be a fuzzy match
+func maxEdits(word string) int {
+	switch n := utf8.RuneCountInString(word); {
+	case n <= 3:
+		return 0
+	case n <= 6:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// splitWords returns the byte ranges of the runs of letters and digits in s
+func splitWords(s string) [][2]int {
+	var words [][2]int
+	start := -1
+	for i, r := range s {
+		inWord := unicode.IsLetter(r) || unicode.IsDigit(r)
+		if inWord && start < 0 {
+			start = i
+		} else if !inWord && start >= 0 {
+			words = append(words, [2]int{start, i})
+			start = -1
+		}
+	}
+	if start >= 0 {
+		words = append(words, [2]int{start, len(s)})
+	}
+	return words
+}
+
+// distance returns the optimal string alignment distance between a and b;
+// the Levenshtein distance, but also counting swapped adjacent letters as a single edit
+// so that "recieve" is one edit away from "receive"
+func distance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	d := make([][]int, len(ra)+1)
+	for i := range d {
+		d[i] = make([]int, len(rb)+1)
+		d[i][0] = i
+	}
+	for j := range d[0] {
+		d[0][j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			d[i][j] = minInt(d[i-1][j]+1, d[i][j-1]+1, d[i-1][j-1]+cost)
+			if i > 1 && j > 1 && ra[i-1] == rb[j-2] && ra[i-2] == rb[j-1] {
+				d[i][j] = minInt(d[i][j], d[i-2][j-2]+1)
+			}
+		}
+	}
+	return d[len(ra)][len(rb)]
+}
+
+func minInt(first int, rest ...int) int {
+	for _, n := range rest {
+		if n < first {
+			first = n
+		}
+	}
+	return first
+}
+
+func sortMatches(matches []note.Match) {
+	// insertion sort; there are only ever a few matches in a line
+	for i := 1; i < len(matches); i++ {
+		for j := i; j > 0 && matches[j].Start < matches[j-1].Start; j-- {
+			matches[j], matches[j-1] = matches[j-1], matches[j]
+		}
+	}
+}
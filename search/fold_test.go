@@ -0,0 +1,115 @@
+package search
+
+import (
+	"testing"
+)
+
+func TestFold(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"Café", "cafe"},
+		{"CAFÉ society", "cafe society"},
+		{"naïve", "naive"},
+		{"ﬁle", "file"}, // compatibility decomposition of the ligature
+		{"日本", "日本"},
+	}
+	for _, tt := range tests {
+		if got, _ := fold(tt.in); got != tt.want {
+			t.Errorf("fold(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestUnfold(t *testing.T) {
+	tests := []struct {
+		in         string
+		start, end int // in the folded string
+		want       string
+	}{
+		{"the Café is open", 4, 8, "Café"},
+		{"ﬁle", 0, 1, "ﬁ"}, // part of a ligature maps back to all of it
+		{"ﬁle", 1, 4, "ﬁle"},
+		{"naïve", 2, 3, "ï"},
+		{"x 日本 y", 2, 8, "日本"},
+	}
+	for _, tt := range tests {
+		folded, offsets := fold(tt.in)
+		if len(offsets) != len(folded) {
+			t.Fatalf("fold(%q) has %d offsets for %d bytes", tt.in, len(offsets), len(folded))
+		}
+		s, e := unfold(tt.in, offsets, tt.start, tt.end)
+		if got := tt.in[s:e]; got != tt.want {
+			t.Errorf("unfold(%q, %d, %d) = %q, want %q", tt.in, tt.start, tt.end, got, tt.want)
+		}
+	}
+}
+
+func TestDistance(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"receive", "receive", 0},
+		{"recieve", "receive", 1}, // swapped letters are one edit
+		{"recive", "receive", 1},
+		{"cafe", "cafes", 1},
+		{"kitten", "sitting", 3},
+	}
+	for _, tt := range tests {
+		if got := distance(tt.a, tt.b); got != tt.want {
+			t.Errorf("distance(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		query string
+		fuzzy bool
+		line  string
+		want  []string // the text of the matches, in order
+		all   bool     // whether every word of the query was seen
+	}{
+		{"cafe", false, "Café society", []string{"Café"}, true},
+		{"receive it", false, "please receive it now", []string{"receive it"}, true},
+		{"receive cafe", false, "I receive a cafe", nil, false},
+		{"receive cafe", true, "I receive a cafe", []string{"receive", "cafe"}, true},
+		{"recieve cafe", true, "I receive a cafe", []string{"receive", "cafe"}, true},
+		{"cafe receive", true, "cafe then receive", []string{"cafe", "receive"}, true},
+		{"recieve", true, "receive and recipes", []string{"receive"}, true},
+		{"dog", true, "the dot", nil, false},         // short words must match exactly
+		{"dog", true, "dogs", []string{"dog"}, true}, // though the text can be part of a word
+		{"#go", false, "#goodcar and #Go", []string{"#Go"}, true},
+	}
+	for _, tt := range tests {
+		m := newMatcher(Query{Text: tt.query, Fuzzy: tt.fuzzy})
+		seen := make([]bool, len(m.words))
+		var got []string
+		for _, match := range m.match(tt.line, seen) {
+			got = append(got, tt.line[match.Start:match.End])
+		}
+		if !equalStrings(got, tt.want) {
+			t.Errorf("%q (fuzzy %v) in %q matched %q, want %q", tt.query, tt.fuzzy, tt.line, got, tt.want)
+		}
+		all := true
+		for _, ok := range seen {
+			all = all && ok
+		}
+		if all != tt.all {
+			t.Errorf("%q (fuzzy %v) in %q saw every word %v, want %v", tt.query, tt.fuzzy, tt.line, all, tt.all)
+		}
+	}
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
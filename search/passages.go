@@ -0,0 +1,42 @@
+package search
+
+import (
+	"strings"
+
+	"oddstream.cj/note"
+	"oddstream.cj/tags"
+)
+
+// Passages returns the parts of a found note that matter to the query, in order, as it is on disk.
+// If the query has hashtags in it, they are the paragraphs and list items the tags are scoped to
+// (see tags.Passages), otherwise they are the paragraphs containing the note's hits.
+func Passages(n *note.Note, q Query) []note.Paragraph {
+	text := n.Stored()
+	var keys []string
+	for _, word := range strings.Fields(q.Text) {
+		if q.Grammar.Valid(word) {
+			keys = append(keys, tags.Key(word))
+		}
+	}
+	if len(keys) == 0 {
+		return note.HitParagraphs(text, n.Hits)
+	}
+	var paras []note.Paragraph
+	for _, key := range keys {
+		for _, p := range q.Grammar.Passages(text, key) {
+			if !containsParagraph(paras, p) {
+				paras = append(paras, p)
+			}
+		}
+	}
+	return paras
+}
+
+func containsParagraph(paras []note.Paragraph, p note.Paragraph) bool {
+	for _, q := range paras {
+		if q.First == p.First && q.Last == p.Last {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,31 @@
+package search
+
+import (
+	"testing"
+
+	"oddstream.cj/note"
+)
+
+func TestPassages(t *testing.T) {
+	directory := writeJournal(t, map[string]string{
+		"01": "#garden\nplanted beans\n\nwent shopping for beans\n\n- #garden weeded\n- beans again\n",
+	})
+	n := note.NewNote("Default", directory, directory+"/2023/07/01.txt")
+	n.Hits = []note.Hit{{Line: 2}, {Line: 4}}
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"beans", []string{"#garden\nplanted beans", "went shopping for beans"}},
+		{"#Garden", []string{"#garden\nplanted beans", "- #garden weeded"}},
+	}
+	for _, tt := range tests {
+		var got []string
+		for _, p := range Passages(n, Query{Text: tt.query}) {
+			got = append(got, p.Text)
+		}
+		if !equalStrings(got, tt.want) {
+			t.Errorf("Passages for %q = %q, want %q", tt.query, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,204 @@
+package search
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"oddstream.cj/tags"
+	"oddstream.cj/util"
+)
+
+// Query is a parsed search string; the text to look for, plus any date filters.
+//
+// Filters are tokens of the form name:value, and are removed from the text:
+//
+//	after:2023-01-01   notes dated on or after 1 Jan 2023
+//	before:2023-03-31  notes dated on or before 31 Mar 2023
+//	after:-30d         notes from the last 30 days (also w, m, y for weeks, months, years)
+//	year:2022          notes from 2022 (a comma-separated list matches any of them)
+//	month:mar          notes from March (a name, abbreviation or number)
+//	weekday:mon        notes written on a Monday
+//
+// Undated notes never match a query with date filters.
+type Query struct {
+	Text    string
+	Fuzzy   bool         // also match words that are near misses
+	Grammar tags.Grammar // what counts as a hashtag in the text
+
+	After    time.Time // zero if not set
+	Before   time.Time // zero if not set
+	Years    []int
+	Months   []time.Month
+	Weekdays []time.Weekday
+}
+
+var filterPattern = regexp.MustCompile(`(?i)(^|\s)(after|before|year|month|weekday):(\S*)`)
+
+// ParseQuery splits str into text and date filters, with relative dates
+// being relative to now
+func ParseQuery(str string, now time.Time) (Query, error) {
+	var q Query
+	var err error
+	text := filterPattern.ReplaceAllStringFunc(str, func(token string) string {
+		if err != nil {
+			return token
+		}
+		name, value, _ := strings.Cut(strings.TrimSpace(token), ":")
+		switch strings.ToLower(name) {
+		case "after":
+			q.After, err = parseDate(value, now)
+		case "before":
+			q.Before, err = parseDate(value, now)
+		case "year":
+			err = parseList(value, func(s string) error {
+				y, err := strconv.Atoi(s)
+				q.Years = append(q.Years, y)
+				return err
+			})
+		case "month":
+			err = parseList(value, func(s string) error {
+				m, err := parseMonth(s)
+				q.Months = append(q.Months, m)
+				return err
+			})
+		case "weekday":
+			err = parseList(value, func(s string) error {
+				d, err := parseWeekday(s)
+				q.Weekdays = append(q.Weekdays, d)
+				return err
+			})
+		}
+		return " "
+	})
+	if err != nil {
+		return Query{}, err
+	}
+	q.Text = strings.TrimSpace(text)
+	return q, nil
+}
+
+// HasFilters reports whether the query restricts the dates of the notes it matches
+func (q Query) HasFilters() bool {
+	return !q.After.IsZero() || !q.Before.IsZero() ||
+		len(q.Years) > 0 || len(q.Months) > 0 || len(q.Weekdays) > 0
+}
+
+// Accept reports whether a note dated t passes the query's date filters
+func (q Query) Accept(t time.Time) bool {
+	if !q.HasFilters() {
+		return true
+	}
+	if t.Year() == 1 {
+		// undated note
+		return false
+	}
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.Local)
+	if !q.After.IsZero() && day.Before(q.After) {
+		return false
+	}
+	if !q.Before.IsZero() && day.After(q.Before) {
+		return false
+	}
+	if len(q.Years) > 0 && !util.Contains(q.Years, t.Year()) {
+		return false
+	}
+	if len(q.Months) > 0 && !util.Contains(q.Months, t.Month()) {
+		return false
+	}
+	if len(q.Weekdays) > 0 && !util.Contains(q.Weekdays, t.Weekday()) {
+		return false
+	}
+	return true
+}
+
+// WithDateRange replaces any after: and before: filters in str with ones for from and to;
+// a zero time leaves that end of the range open
+func WithDateRange(str string, from, to time.Time) string {
+	str = filterPattern.ReplaceAllStringFunc(str, func(token string) string {
+		name, _, _ := strings.Cut(strings.TrimSpace(token), ":")
+		switch strings.ToLower(name) {
+		case "after", "before":
+			return ""
+		}
+		return token
+	})
+	str = strings.TrimSpace(str)
+	if !from.IsZero() {
+		str += " after:" + from.Format("2006-01-02")
+	}
+	if !to.IsZero() {
+		str += " before:" + to.Format("2006-01-02")
+	}
+	return strings.TrimSpace(str)
+}
+
+func parseList(value string, fn func(string) error) error {
+	if value == "" {
+		return fmt.Errorf("missing filter value")
+	}
+	for _, s := range strings.Split(value, ",") {
+		if err := fn(strings.ToLower(s)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parseDate accepts an ISO 8601 date like 2023-01-31,
+// or a relative date like -30d, -2w, -3m or -1y
+func parseDate(value string, now time.Time) (time.Time, error) {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+	if strings.HasPrefix(value, "-") || strings.HasPrefix(value, "+") {
+		if len(value) < 3 {
+			return time.Time{}, fmt.Errorf("bad relative date %q", value)
+		}
+		n, err := strconv.Atoi(value[:len(value)-1])
+		if err != nil {
+			return time.Time{}, fmt.Errorf("bad relative date %q", value)
+		}
+		switch strings.ToLower(value[len(value)-1:]) {
+		case "d":
+			return today.AddDate(0, 0, n), nil
+		case "w":
+			return today.AddDate(0, 0, n*7), nil
+		case "m":
+			return today.AddDate(0, n, 0), nil
+		case "y":
+			return today.AddDate(n, 0, 0), nil
+		}
+		return time.Time{}, fmt.Errorf("bad relative date %q", value)
+	}
+	t, err := time.ParseInLocation("2006-01-02", value, time.Local)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("bad date %q", value)
+	}
+	return t, nil
+}
+
+func parseMonth(s string) (time.Month, error) {
+	if m, err := strconv.Atoi(s); err == nil && m >= 1 && m <= 12 {
+		return time.Month(m), nil
+	}
+	if len(s) >= 3 {
+		for m := time.January; m <= time.December; m++ {
+			if strings.HasPrefix(strings.ToLower(m.String()), s) {
+				return m, nil
+			}
+		}
+	}
+	return 0, fmt.Errorf("bad month %q", s)
+}
+
+func parseWeekday(s string) (time.Weekday, error) {
+	if len(s) >= 2 {
+		for d := time.Sunday; d <= time.Saturday; d++ {
+			if strings.HasPrefix(strings.ToLower(d.String()), s) {
+				return d, nil
+			}
+		}
+	}
+	return 0, fmt.Errorf("bad weekday %q", s)
+}
@@ -0,0 +1,75 @@
+package search
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseQuery(t *testing.T) {
+	now := time.Date(2023, time.July, 20, 15, 4, 0, 0, time.Local)
+	day := func(y int, m time.Month, d int) time.Time { return time.Date(y, m, d, 0, 0, 0, 0, time.Local) }
+	tests := []struct {
+		str  string
+		want Query
+	}{
+		{"dog food", Query{Text: "dog food"}},
+		{"dog after:2023-01-01 before:2023-03-31", Query{Text: "dog", After: day(2023, 1, 1), Before: day(2023, 3, 31)}},
+		{"after:-30d", Query{After: day(2023, 6, 20)}},
+		{"after:-2w", Query{After: day(2023, 7, 6)}},
+		{"after:-1m", Query{After: day(2023, 6, 20)}},
+		{"before:+1y", Query{Before: day(2024, 7, 20)}},
+		{"YEAR:2021,2022 cat", Query{Text: "cat", Years: []int{2021, 2022}}},
+		{"month:mar,12 weekday:mon,fri", Query{Months: []time.Month{time.March, time.December}, Weekdays: []time.Weekday{time.Monday, time.Friday}}},
+		{"http://example.com", Query{Text: "http://example.com"}},
+	}
+	for _, tt := range tests {
+		got, err := ParseQuery(tt.str, now)
+		if err != nil {
+			t.Errorf("ParseQuery(%q) failed: %s", tt.str, err)
+			continue
+		}
+		if got.Text != tt.want.Text || !got.After.Equal(tt.want.After) || !got.Before.Equal(tt.want.Before) ||
+			!equalSlices(got.Years, tt.want.Years) || !equalSlices(got.Months, tt.want.Months) || !equalSlices(got.Weekdays, tt.want.Weekdays) {
+			t.Errorf("ParseQuery(%q) = %+v, want %+v", tt.str, got, tt.want)
+		}
+	}
+}
+
+func TestParseQueryErrors(t *testing.T) {
+	for _, str := range []string{"after:2023-02-30", "after:-3x", "before:-d", "year:", "year:twenty", "month:ju", "weekday:x"} {
+		if _, err := ParseQuery(str, time.Now()); err == nil {
+			t.Errorf("ParseQuery(%q) didn't fail", str)
+		}
+	}
+}
+
+func TestAccept(t *testing.T) {
+	q, _ := ParseQuery("after:2023-07-01 before:2023-07-31 weekday:tue", time.Now())
+	tests := []struct {
+		date time.Time
+		want bool
+	}{
+		{time.Date(2023, 7, 4, 0, 0, 0, 0, time.Local), true},
+		{time.Date(2023, 7, 5, 0, 0, 0, 0, time.Local), false}, // a Wednesday
+		{time.Date(2023, 6, 27, 0, 0, 0, 0, time.Local), false},
+		{time.Date(2023, 8, 1, 0, 0, 0, 0, time.Local), false},
+		{time.Time{}, false}, // undated
+	}
+	for _, tt := range tests {
+		if got := q.Accept(tt.date); got != tt.want {
+			t.Errorf("Accept(%s) = %v, want %v", tt.date.Format("Mon 2 Jan 2006"), got, tt.want)
+		}
+	}
+}
+
+func equalSlices[T comparable](a, b []T) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,145 @@
+package search
+
+import (
+	"math"
+	"sort"
+
+	"oddstream.cj/note"
+)
+
+// Order is a way of sorting found notes
+type Order int
+
+const (
+	DateAscending Order = iota
+	DateDescending
+	Relevance
+	HitCount
+)
+
+// Orders lists the sort orders in the order they are offered to the user
+var Orders = []Order{DateAscending, DateDescending, Relevance, HitCount}
+
+func (o Order) String() string {
+	switch o {
+	case DateDescending:
+		return "Newest first"
+	case Relevance:
+		return "Relevance"
+	case HitCount:
+		return "Most hits"
+	default:
+		return "Oldest first"
+	}
+}
+
+// BM25 tuning constants, the usual defaults
+const (
+	bm25K1 = 1.2
+	bm25B  = 0.75
+)
+
+// Rank sets the Score of each found note using Okapi BM25, summed over the words of the query,
+// or its tag if it is just a hashtag. A word's frequency in a note is how often it was matched,
+// with fuzzy matches counting for half as much. The collection is the notes of the vocabularies,
+// one for each journal searched, which know how often each word is used without rereading the notes.
+func Rank(found []*note.Note, q Query, vocabularies []*Vocabulary) {
+	m := newMatcher(q)
+	terms := m.words
+	if m.tag != "" {
+		terms = []string{m.tag}
+	}
+	if len(found) == 0 || len(terms) == 0 {
+		return
+	}
+	var docs, length int
+	df := make([]int, len(terms))
+	for _, v := range vocabularies {
+		v.lock.RLock()
+		docs += v.docs
+		length += v.length
+		for i, t := range terms {
+			df[i] += v.df[t]
+		}
+		v.lock.RUnlock()
+	}
+	if docs == 0 || length == 0 {
+		return
+	}
+	avgLength := float64(length) / float64(docs)
+	idf := make([]float64, len(terms))
+	for i := range terms {
+		d := math.Min(float64(df[i]), float64(docs))
+		idf[i] = math.Log((float64(docs)-d+0.5)/(d+0.5) + 1)
+	}
+	for _, n := range found {
+		size := avgLength
+		for _, v := range vocabularies {
+			v.lock.RLock()
+			s, ok := v.sizes[n.Pathname]
+			v.lock.RUnlock()
+			if ok {
+				size = float64(s)
+				break
+			}
+		}
+		n.Score = 0
+		for i, tf := range m.frequencies(n) {
+			n.Score += idf[i] * tf * (bm25K1 + 1) / (tf + bm25K1*(1-bm25B+bm25B*size/avgLength))
+		}
+	}
+}
+
+// frequencies returns how often each word of the query, or its tag, was matched in a found note.
+// A match of the whole query text counts for every word, and fuzzy matches count for half.
+func (m *matcher) frequencies(n *note.Note) []float64 {
+	if m.tag != "" {
+		return []float64{float64(n.HitCount())}
+	}
+	tf := make([]float64, len(m.words))
+	for _, h := range n.Hits {
+		for _, match := range h.Matches {
+			text, _ := fold(h.Text[match.Start:match.End])
+			if text == m.phrase {
+				for i := range tf {
+					tf[i]++
+				}
+				continue
+			}
+			for i, w := range m.words {
+				if text == w {
+					tf[i]++
+					break
+				} else if distance(text, w) <= maxEdits(w) {
+					tf[i] += 0.5
+					break
+				}
+			}
+		}
+	}
+	return tf
+}
+
+// Sort puts found notes into order, breaking ties by date.
+// When sorting by relevance, notes with exact matches come before those with only fuzzy ones.
+func Sort(found []*note.Note, order Order) {
+	sort.SliceStable(found, func(i, j int) bool {
+		a, b := found[i], found[j]
+		switch order {
+		case DateDescending:
+			return a.Date.After(b.Date)
+		case Relevance:
+			if a.Exact() != b.Exact() {
+				return a.Exact()
+			}
+			if a.Score != b.Score {
+				return a.Score > b.Score
+			}
+		case HitCount:
+			if a.HitCount() != b.HitCount() {
+				return a.HitCount() > b.HitCount()
+			}
+		}
+		return a.Date.Before(b.Date)
+	})
+}
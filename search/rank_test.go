@@ -0,0 +1,57 @@
+package search
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"oddstream.cj/note"
+	"oddstream.cj/tags"
+)
+
+func TestRank(t *testing.T) {
+	directory := writeJournal(t, map[string]string{
+		"01": "apple apple quince\n",
+		"02": "apple quince quince\n",
+		"03": "apple\n",
+		"04": "apple pie\n",
+		"05": "apple crumble\n",
+	})
+	v := NewVocabulary(tags.Grammar{})
+	x := note.NewIndex("Default", directory, v)
+	rank := func() (a, b float64) {
+		q := Query{Text: "apple quince", Fuzzy: true}
+		found, err := Find(context.Background(), filepath.Dir(directory), []string{"Default"}, q, nil)
+		if err != nil || len(found) != 2 {
+			t.Fatalf("Find found %d notes, %v", len(found), err)
+		}
+		Rank(found, q, []*Vocabulary{v})
+		return found[0].Score, found[1].Score
+	}
+	// quince is rarer than apple, so a note using it more is more relevant
+	if a, b := rank(); a <= 0 || b <= a {
+		t.Errorf("scores are %.3f and %.3f, want the second higher", a, b)
+	}
+
+	// once apple is as rare as quince, the two notes are as relevant as each other
+	for _, day := range []string{"03", "04", "05"} {
+		pathname := filepath.Join(directory, "2023", "07", day+".txt")
+		os.Remove(pathname)
+		x.Update(pathname)
+	}
+	if a, b := rank(); a != b {
+		t.Errorf("scores are %.3f and %.3f, want them the same", a, b)
+	}
+}
+
+func TestFrequencies(t *testing.T) {
+	n := &note.Note{Hits: []note.Hit{
+		{Text: "Apple quince", Matches: []note.Match{{Start: 0, End: 12}}},
+		{Text: "an aple and a quince", Matches: []note.Match{{Start: 3, End: 7, Fuzzy: true}, {Start: 14, End: 20}}},
+	}}
+	got := newMatcher(Query{Text: "apple quince", Fuzzy: true}).frequencies(n)
+	if len(got) != 2 || got[0] != 1.5 || got[1] != 2 {
+		t.Errorf("frequencies = %v, want [1.5 2]", got)
+	}
+}
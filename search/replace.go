@@ -0,0 +1,98 @@
+package search
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"oddstream.cj/note"
+)
+
+// Occurrence is one place in a note where some text was found, for find and replace
+type Occurrence struct {
+	Note   *note.Note
+	Hit    note.Hit // the line containing the occurrence, with just this one match
+	Offset int      // byte offset of the occurrence within the note's text
+	Length int      // length in bytes of the occurrence
+}
+
+// Occurrences loads the notes of the journal kept in directory and returns every occurrence of text in them.
+// Unlike Find, the text must match literally, ignoring case only if matchCase is false.
+func Occurrences(journal, directory string, text string, matchCase bool) []Occurrence {
+	var occs []Occurrence
+	if text == "" {
+		return occs
+	}
+	note.Walk(journal, directory, func(n *note.Note) error {
+		n.Load()
+		offset := 0
+		for i, line := range strings.SplitAfter(n.Text, "\n") {
+			for _, m := range literalMatches(line, text, matchCase) {
+				occs = append(occs, Occurrence{
+					Note:   n,
+					Hit:    note.Hit{Line: i + 1, Text: strings.TrimRight(line, "\r\n"), Matches: []note.Match{{Start: m[0], End: m[1]}}},
+					Offset: offset + m[0],
+					Length: m[1] - m[0],
+				})
+			}
+			offset += len(line)
+		}
+		return nil
+	})
+	sort.SliceStable(occs, func(i, j int) bool {
+		return occs[i].Note.Date.Before(occs[j].Note.Date)
+	})
+	return occs
+}
+
+// Replace returns content with the occurrences, which must all be from the same note, replaced.
+// It fails if content no longer has the occurrences where they were found,
+// which means the note has been changed since.
+func Replace(content string, occs []Occurrence, replacement string) (string, error) {
+	sorted := append([]Occurrence{}, occs...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Offset > sorted[j].Offset
+	})
+	for _, o := range sorted {
+		end := o.Offset + o.Length
+		found := o.Hit.Text[o.Hit.Matches[0].Start:o.Hit.Matches[0].End]
+		if end > len(content) || content[o.Offset:end] != found {
+			return "", fmt.Errorf("%s has changed since it was searched", o.Note.Pathname)
+		}
+		content = content[:o.Offset] + replacement + content[end:]
+	}
+	return content, nil
+}
+
+// literalMatches returns the byte ranges of each occurrence of text in line
+func literalMatches(line string, text string, matchCase bool) [][2]int {
+	var matches [][2]int
+	if matchCase {
+		for from := 0; from < len(line); {
+			i := strings.Index(line[from:], text)
+			if i < 0 {
+				break
+			}
+			matches = append(matches, [2]int{from + i, from + i + len(text)})
+			from += i + len(text)
+		}
+		return matches
+	}
+	n := utf8.RuneCountInString(text)
+	for i := 0; i < len(line); {
+		j := i
+		for k := 0; k < n && j < len(line); k++ {
+			_, size := utf8.DecodeRuneInString(line[j:])
+			j += size
+		}
+		if strings.EqualFold(line[i:j], text) {
+			matches = append(matches, [2]int{i, j})
+			i = j
+			continue
+		}
+		_, size := utf8.DecodeRuneInString(line[i:])
+		i += size
+	}
+	return matches
+}
@@ -0,0 +1,89 @@
+package search
+
+import (
+	"testing"
+)
+
+func TestLiteralMatches(t *testing.T) {
+	tests := []struct {
+		line, text string
+		matchCase  bool
+		want       [][2]int
+	}{
+		{"a Cat and a cat", "cat", true, [][2]int{{12, 15}}},
+		{"a Cat and a cat", "cat", false, [][2]int{{2, 5}, {12, 15}}},
+		{"aaaa", "aa", true, [][2]int{{0, 2}, {2, 4}}}, // repeats don't overlap
+		{"aaaaa", "AA", false, [][2]int{{0, 2}, {2, 4}}},
+		{"CAFÉ café", "café", false, [][2]int{{0, 5}, {6, 11}}},
+		// the Kelvin sign and long s fold to ASCII, so a match can be longer than the text
+		{"Kelvin", "kelvin", false, [][2]int{{0, 8}}},
+		{"the ſun", "sun", false, [][2]int{{4, 8}}},
+		{"Kelvin", "kelvin", true, nil},
+		{"café", "cafe", false, nil}, // unlike Find, accents count
+		{"", "x", false, nil},
+	}
+	for _, tt := range tests {
+		got := literalMatches(tt.line, tt.text, tt.matchCase)
+		if len(got) != len(tt.want) {
+			t.Errorf("literalMatches(%q, %q, %v) = %v, want %v", tt.line, tt.text, tt.matchCase, got, tt.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Errorf("literalMatches(%q, %q, %v) = %v, want %v", tt.line, tt.text, tt.matchCase, got, tt.want)
+				break
+			}
+		}
+	}
+}
+
+func TestOccurrencesAndReplace(t *testing.T) {
+	directory := writeJournal(t, map[string]string{
+		"02": "the King\nking of kings\n",
+		"01": "no royalty here\n",
+		"03": "KING\r\n",
+	})
+	occs := Occurrences("Default", directory, "king", false)
+	var where []string
+	for _, o := range occs {
+		where = append(where, o.Note.Date.Format("02")+":"+o.Hit.Text[o.Hit.Matches[0].Start:o.Hit.Matches[0].End])
+	}
+	want := []string{"02:King", "02:king", "02:king", "03:KING"}
+	if !equalStrings(where, want) {
+		t.Fatalf("Occurrences found %q, want %q", where, want)
+	}
+	if occs[3].Hit.Text != "KING" {
+		t.Errorf("the hit line is %q, without its line ending", occs[3].Hit.Text)
+	}
+
+	content := "the King\nking of kings\n"
+	got, err := Replace(content, occs[:3], "queen")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "the queen\nqueen of queens\n"; got != want {
+		t.Errorf("Replace gave %q, want %q", got, want)
+	}
+	// only some of them
+	got, err = Replace(content, occs[1:2], "queen")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "the King\nqueen of kings\n"; got != want {
+		t.Errorf("Replace gave %q, want %q", got, want)
+	}
+}
+
+func TestReplaceStale(t *testing.T) {
+	directory := writeJournal(t, map[string]string{"01": "one cat\ntwo cats\n"})
+	occs := Occurrences("Default", directory, "cat", true)
+	for _, content := range []string{
+		"one dog\ntwo cats\n",    // edited where it was found
+		"a\none cat\ntwo cats\n", // moved
+		"one ca",                 // cut short
+	} {
+		if got, err := Replace(content, occs, "dog"); err == nil {
+			t.Errorf("Replace(%q) = %q, want an error as the note has changed", content, got)
+		}
+	}
+}
@@ -0,0 +1,142 @@
+package search
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"oddstream.cj/note"
+)
+
+// Find returns the notes of the journals, which are directories of dataDir, that contain the query text and pass its date filters,
+// sorted by date, with each note's Hits set to the lines that matched, ready to be scored by Rank.
+// A query with filters but no text finds every note that passes the filters.
+//
+// If progress is not nil, it is called with a copy of each note as soon as it is found,
+// so that the copy can be read by another goroutine while the notes Find returns go on to be ranked.
+// If ctx is cancelled, Find gives up and returns ctx's error.
+//
+// Matching ignores case and diacritics, so "cafe" finds "Café". If the query is fuzzy,
+// words that are a typo or two away from the words of the query also match, and
+// a note matches if it contains every word of the query, exactly or fuzzily.
+func Find(ctx context.Context, dataDir string, journals []string, q Query, progress func(*note.Note)) ([]*note.Note, error) {
+	var found []*note.Note
+
+	if q.Text == "" && !q.HasFilters() {
+		return found, nil
+	}
+
+	m := newMatcher(q)
+	for _, journal := range journals {
+		err := note.Walk(journal, filepath.Join(dataDir, journal), func(n *note.Note) error {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			if !q.Accept(n.Date) {
+				return nil
+			}
+			if q.Text != "" && !scan(n, m) {
+				return nil
+			}
+			found = append(found, n)
+			if progress != nil {
+				c := *n
+				progress(&c)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	Sort(found, DateAscending)
+
+	return found, nil
+}
+
+// scan reads a note from disk and sets its hits, reporting whether it matched
+func scan(n *note.Note, m *matcher) bool {
+	content, err := os.ReadFile(n.Pathname)
+	if err != nil {
+		return false
+	}
+	// like grep -I, don't process binary files
+	if bytes.IndexByte(content[:minInt(len(content), 8000)], 0) >= 0 {
+		return false
+	}
+	seen := make([]bool, len(m.words))
+	for i, line := range strings.Split(string(content), "\n") {
+		if matches := m.match(line, seen); len(matches) > 0 {
+			n.Hits = append(n.Hits, note.Hit{Line: i + 1, Text: line, Matches: matches})
+		}
+	}
+	for _, ok := range seen {
+		if !ok {
+			n.Hits = nil
+			return false
+		}
+	}
+	return len(n.Hits) > 0
+}
+
+// Locate returns the matches of the query's text anywhere in text, as byte offsets into it,
+// for finding the hits within a note being edited
+func Locate(text string, q Query) []note.Match {
+	var matches []note.Match
+	m := newMatcher(q)
+	seen := make([]bool, len(m.words))
+	offset := 0
+	for _, line := range strings.SplitAfter(text, "\n") {
+		for _, match := range m.match(strings.TrimRight(line, "\r\n"), seen) {
+			match.Start += offset
+			match.End += offset
+			matches = append(matches, match)
+		}
+		offset += len(line)
+	}
+	return matches
+}
+
+// Snippet trims a hit to about width runes around its first match,
+// adding ellipses where text has been cut and adjusting the matches to suit
+func Snippet(h note.Hit, width int) note.Hit {
+	text := strings.TrimRight(h.Text, "\r")
+	if utf8.RuneCountInString(text) <= width || len(h.Matches) == 0 {
+		return note.Hit{Line: h.Line, Text: text, Matches: clip(h.Matches, 0, len(text), 0)}
+	}
+	// start a quarter of the width before the first match, on a rune boundary
+	start := h.Matches[0].Start
+	for k := 0; k < width/4 && start > 0; k++ {
+		_, size := utf8.DecodeLastRuneInString(text[:start])
+		start -= size
+	}
+	end := start
+	for k := 0; k < width && end < len(text); k++ {
+		_, size := utf8.DecodeRuneInString(text[end:])
+		end += size
+	}
+	var prefix, suffix string
+	if start > 0 {
+		prefix = "…"
+	}
+	if end < len(text) {
+		suffix = "…"
+	}
+	matches := clip(h.Matches, start, end, len(prefix)-start)
+	return note.Hit{Line: h.Line, Text: prefix + text[start:end] + suffix, Matches: matches}
+}
+
+// clip returns the matches that lie within the byte range [start, end), moved by shift bytes
+func clip(matches []note.Match, start, end, shift int) []note.Match {
+	var result []note.Match
+	for _, m := range matches {
+		if m.Start >= start && m.End <= end {
+			result = append(result, note.Match{Start: m.Start + shift, End: m.End + shift, Fuzzy: m.Fuzzy})
+		}
+	}
+	return result
+}
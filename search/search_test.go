@@ -0,0 +1,124 @@
+package search
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"oddstream.cj/note"
+)
+
+// writeJournal makes a journal called Default in a temporary directory with a note for each day of July 2023 in notes
+func writeJournal(t *testing.T, notes map[string]string) string {
+	t.Helper()
+	directory := filepath.Join(t.TempDir(), "Default")
+	for day, text := range notes {
+		pathname := filepath.Join(directory, "2023", "07", day+".txt")
+		if err := os.MkdirAll(filepath.Dir(pathname), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(pathname, []byte(text), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return directory
+}
+
+func TestFindEveryWord(t *testing.T) {
+	directory := writeJournal(t, map[string]string{
+		"01": "I receive a cafe\n",
+		"02": "cafe then\nreceive later\n",
+		"03": "only a cafe\n",
+	})
+	tests := []struct {
+		query string
+		fuzzy bool
+		want  []string // days found
+	}{
+		{"cafe", false, []string{"01", "02", "03"}},
+		{"receive cafe", false, nil},
+		{"receive cafe", true, []string{"01", "02"}},
+		{"recieve cafe", true, []string{"01", "02"}},
+		{"cafe receive", true, []string{"01", "02"}},
+		{"cafe parcel", true, nil},
+	}
+	for _, tt := range tests {
+		found, err := Find(context.Background(), filepath.Dir(directory), []string{"Default"}, Query{Text: tt.query, Fuzzy: tt.fuzzy}, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []string
+		for _, n := range found {
+			got = append(got, n.Date.Format("02"))
+		}
+		if !equalStrings(got, tt.want) {
+			t.Errorf("Find(%q, fuzzy %v) found %v, want %v", tt.query, tt.fuzzy, got, tt.want)
+		}
+	}
+}
+
+func TestFindJournals(t *testing.T) {
+	directory := writeJournal(t, map[string]string{"01": "cafe\n"})
+	dataDir := filepath.Dir(directory)
+	pathname := filepath.Join(dataDir, "Work", "2023", "07", "02.txt")
+	if err := os.MkdirAll(filepath.Dir(pathname), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(pathname, []byte("cafe\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	found, err := Find(context.Background(), dataDir, []string{"Default", "Work"}, Query{Text: "cafe"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, n := range found {
+		got = append(got, n.Journal)
+	}
+	if want := []string{"Default", "Work"}; !equalStrings(got, want) {
+		t.Errorf("Find found notes in %q, want %q", got, want)
+	}
+}
+
+func TestFindCancelled(t *testing.T) {
+	directory := writeJournal(t, map[string]string{"01": "cafe\n"})
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := Find(ctx, filepath.Dir(directory), []string{"Default"}, Query{Text: "cafe"}, nil); err == nil {
+		t.Error("Find didn't give up when cancelled")
+	}
+}
+
+func TestSnippet(t *testing.T) {
+	tests := []struct {
+		text       string
+		start, end int // of the only match
+		width      int
+		want       string
+		match      string // the text of the match in the snippet, or "" if it was clipped
+	}{
+		{"a short line", 2, 7, 40, "a short line", "short"},
+		{"the quick brown fox jumps over the lazy dog", 16, 19, 12, "…wn fox jumps…", "fox"},
+		{"the quick brown fox jumps over the lazy dog", 0, 3, 12, "the quick br…", "the"},
+		{"the quick brown fox jumps over the lazy dog", 40, 43, 12, "…zy dog", "dog"},
+		{"café café café café café", 12, 17, 8, "…é café c…", "café"},
+		// a match that doesn't fit in the width is dropped rather than cut
+		{"aaaa bbbbbbbbbbbbbbbbbbbb", 5, 25, 8, "…a bbbbbb…", ""},
+	}
+	for _, tt := range tests {
+		h := note.Hit{Line: 1, Text: tt.text, Matches: []note.Match{{Start: tt.start, End: tt.end}}}
+		got := Snippet(h, tt.width)
+		if got.Text != tt.want {
+			t.Errorf("Snippet(%q, %d) = %q, want %q", tt.text, tt.width, got.Text, tt.want)
+			continue
+		}
+		var match string
+		if len(got.Matches) > 0 {
+			match = got.Text[got.Matches[0].Start:got.Matches[0].End]
+		}
+		if match != tt.match {
+			t.Errorf("Snippet(%q, %d) match is %q, want %q", tt.text, tt.width, match, tt.match)
+		}
+	}
+}
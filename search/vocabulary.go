@@ -0,0 +1,122 @@
+package search
+
+import (
+	"strings"
+	"sync"
+	"unicode"
+
+	"oddstream.cj/note"
+	"oddstream.cj/tags"
+)
+
+// minVocabularyWord is the length of the shortest word worth suggesting
+const minVocabularyWord = 4
+
+// Vocabulary counts the words, lower-cased, and the hashtags, by key, used in the notes of a journal,
+// and keeps the statistics Rank needs; Rank may read them while the vocabulary is being changed
+type Vocabulary struct {
+	grammar tags.Grammar
+	notes   map[string]usage // keyed by pathname
+	words   map[string]int
+	tags    map[string]int
+
+	lock   sync.RWMutex   // guards the statistics below
+	docs   int            // number of notes
+	length int            // total length of the notes, in words
+	df     map[string]int // number of notes using each folded word, or tag by key
+	sizes  map[string]int // length of each note in words, by pathname
+}
+
+// usage is how often each word and tag is used in a note
+type usage struct {
+	words map[string]int
+	tags  map[string]int
+	terms []string // the distinct folded words and tag keys
+}
+
+// NewVocabulary returns an empty vocabulary, with tags written in grammar g
+func NewVocabulary(g tags.Grammar) *Vocabulary {
+	return &Vocabulary{grammar: g, notes: make(map[string]usage), words: make(map[string]int), tags: make(map[string]int),
+		df: make(map[string]int), sizes: make(map[string]int)}
+}
+
+// Index counts the words and tags in a note
+func (v *Vocabulary) Index(n *note.Note) {
+	v.lock.Lock()
+	defer v.lock.Unlock()
+	if old, ok := v.notes[n.Pathname]; ok {
+		subtract(v.words, old.words)
+		subtract(v.tags, old.tags)
+		for _, t := range old.terms {
+			if v.df[t]--; v.df[t] <= 0 {
+				delete(v.df, t)
+			}
+		}
+		delete(v.notes, n.Pathname)
+	}
+	if size, ok := v.sizes[n.Pathname]; ok {
+		v.docs--
+		v.length -= size
+		delete(v.sizes, n.Pathname)
+	}
+	if n.Text == "" {
+		return // it has been removed
+	}
+	u := usage{words: make(map[string]int), tags: make(map[string]int)}
+	for _, t := range v.grammar.Tokenize(n.Text) {
+		u.tags[t.Key]++
+	}
+	folded, _ := fold(n.Text)
+	seen := make(map[string]bool)
+	words := splitWords(folded)
+	for _, w := range words {
+		seen[folded[w[0]:w[1]]] = true
+	}
+	for t := range u.tags {
+		seen[t] = true
+	}
+	for t := range seen {
+		u.terms = append(u.terms, t)
+		v.df[t]++
+	}
+	v.docs++
+	v.length += len(words)
+	v.sizes[n.Pathname] = len(words)
+	text := strings.ToLower(n.Text)
+	for _, w := range splitWords(text) {
+		word := text[w[0]:w[1]]
+		if w[0] > 0 && text[w[0]-1] == '#' {
+			continue // part of a tag
+		} else if len([]rune(word)) >= minVocabularyWord && !unicode.IsDigit([]rune(word)[0]) {
+			u.words[word]++
+		}
+	}
+	if len(u.terms) == 0 {
+		return
+	}
+	for w, count := range u.words {
+		v.words[w] += count
+	}
+	for t, count := range u.tags {
+		v.tags[t] += count
+	}
+	v.notes[n.Pathname] = u
+}
+
+func subtract(total map[string]int, counts map[string]int) {
+	for k, count := range counts {
+		if total[k] -= count; total[k] <= 0 {
+			delete(total, k)
+		}
+	}
+}
+
+// Words returns how often each word is used, lower-cased; it must not be changed
+func (v *Vocabulary) Words() map[string]int {
+	return v.words
+}
+
+// Tags returns how often each hashtag is used, by key; it must not be changed
+func (v *Vocabulary) Tags() map[string]int {
+	return v.tags
+}
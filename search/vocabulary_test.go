@@ -0,0 +1,49 @@
+package search
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"oddstream.cj/note"
+	"oddstream.cj/tags"
+)
+
+func TestVocabulary(t *testing.T) {
+	directory := writeJournal(t, map[string]string{
+		"01": "Walked the dog #walks\n",
+		"02": "walked again, 2023 #Walks #🐈\n",
+	})
+	v := NewVocabulary(tags.Grammar{})
+	x := note.NewIndex("Default", directory, v)
+	if got := v.Words()["walked"]; got != 2 {
+		t.Errorf("walked used %d times, want 2", got)
+	}
+	if got := v.Words()["walks"]; got != 0 {
+		t.Errorf("tag counted as a word %d times", got)
+	}
+	if got := v.Tags()["#walks"]; got != 2 {
+		t.Errorf("#walks used %d times, want 2", got)
+	}
+
+	pathname := filepath.Join(directory, "2023", "07", "02.txt")
+	if err := os.WriteFile(pathname, []byte("ran instead #runs\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	x.Update(pathname)
+	if got := v.Words()["walked"]; got != 1 {
+		t.Errorf("after update, walked used %d times, want 1", got)
+	}
+	if _, ok := v.Tags()["#🐈"]; ok {
+		t.Error("after update, #🐈 still counted")
+	}
+	if got := v.Words()["instead"]; got != 1 {
+		t.Errorf("after update, instead used %d times, want 1", got)
+	}
+
+	os.Remove(pathname)
+	x.Update(pathname)
+	if _, ok := v.Tags()["#runs"]; ok {
+		t.Error("after removing the note, #runs still counted")
+	}
+}
@@ -0,0 +1,526 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"sort"
+	"sync"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+	"oddstream.cj/fynex"
+	"oddstream.cj/note"
+	"oddstream.cj/search"
+	"oddstream.cj/tags"
+)
+
+// ways of refining the found notes with another query, see findEx
+const (
+	opWiden   = "widen"   // union
+	opNarrow  = "narrow"  // intersection
+	opExclude = "exclude" // difference
+)
+
+// refinement is a query whose results have been combined with the found notes
+type refinement struct {
+	Op    string `json:"op"`
+	Query string `json:"query"`
+}
+
+// savedSearch is a query and its refinements, which can be run again to recreate the found notes
+type savedSearch struct {
+	Name        string       `json:"name,omitempty"`
+	Query       string       `json:"query"`
+	Fuzzy       bool         `json:"fuzzy,omitempty"`
+	AllJournals bool         `json:"allJournals,omitempty"`
+	Steps       []refinement `json:"steps,omitempty"`
+}
+
+// results are the notes found by a search's query, followed by those found by each of its refinements;
+// keeping them means a refinement can be taken away without searching again
+type results [][]*note.Note
+
+// liveSearchDelay is how long to wait for the user to stop typing before searching
+const liveSearchDelay = 300 * time.Millisecond
+
+var (
+	theSearch      savedSearch    // the search that produced theFound, named if it is a saved search
+	theResults     results        // the notes found by theSearch's query and refinements, which combine to make theFound
+	theSavedShown  []savedSearch  // the saved searches as the saved list shows them, copied so the list can be refreshed from any goroutine
+	theSavedCounts map[string]int // the number of notes found by each saved search, by name, last time they were run
+	theFoundSerial int            // incremented whenever the found notes are replaced by another search's
+
+	theFoundLock      sync.Mutex         // guards theFound, theResults, theSavedShown, theSavedCounts and theFoundSerial, which searches update from other goroutines
+	liveSearchTimer   *time.Timer        // delays live searching until the user stops typing
+	liveSearchCancel  context.CancelFunc // cancels the live search in progress, if any
+	savedSearchCancel context.CancelFunc // cancels the rerunning of the saved searches, if under way
+)
+
+// newSearch returns a search for query, using the current search options
+func newSearch(query string) savedSearch {
+	return savedSearch{Query: query, Fuzzy: theFuzzyMode, AllJournals: theAllJournals}
+}
+
+// searchScope is where a search looks, and how it sorts what it finds;
+// it is worked out when the search starts, so a search running in the background doesn't read
+// globals that the user may be changing
+type searchScope struct {
+	dataDir      string
+	journals     []string
+	order        search.Order
+	grammar      tags.Grammar
+	vocabularies []*search.Vocabulary // of each journal, for ranking what is found
+}
+
+// scope returns where the search looks, using the current journal and sort order
+func (s savedSearch) scope() (searchScope, error) {
+	sc := searchScope{dataDir: path.Join(theUserHomeDir, theDataDir), journals: []string{theJournalDir}, order: theSortOrder, grammar: theGrammar}
+	if s.AllJournals {
+		names, err := journalNames()
+		if err != nil {
+			return searchScope{}, err
+		}
+		sc.journals = names
+	}
+	for _, name := range sc.journals {
+		sc.vocabularies = append(sc.vocabularies, vocabularyOf(name))
+	}
+	return sc, nil
+}
+
+// vocabularyOf returns the vocabulary of a journal, reading its notes the first time it is searched;
+// the other journals don't change until they become the current one, when their vocabulary is read again
+func vocabularyOf(name string) *search.Vocabulary {
+	v, ok := theVocabularies[name]
+	if !ok {
+		v = search.NewVocabulary(theGrammar)
+		note.NewIndex(name, path.Join(theUserHomeDir, theDataDir, name), v)
+		theVocabularies[name] = v
+	}
+	return v
+}
+
+// run performs a search and its refinements
+func (s savedSearch) run() (results, error) {
+	sc, err := s.scope()
+	if err != nil {
+		return nil, err
+	}
+	return s.runContext(context.Background(), sc, nil)
+}
+
+// runContext performs a search and its refinements, giving up if ctx is cancelled.
+// If the search has no refinements, progress is called with each note as it is found.
+func (s savedSearch) runContext(ctx context.Context, sc searchScope, progress func(*note.Note)) (results, error) {
+	if len(s.Steps) > 0 {
+		progress = nil // the notes found first may be refined away
+	}
+	found, err := s.find(ctx, sc, s.Query, progress)
+	if err != nil {
+		return nil, err
+	}
+	r := results{found}
+	for _, step := range s.Steps {
+		found, err := s.find(ctx, sc, step.Query, nil)
+		if err != nil {
+			return nil, err
+		}
+		r = append(r, found)
+	}
+	return r, nil
+}
+
+// combine works out the found notes by applying each refinement in turn to the notes found by the query
+func (r results) combine(steps []refinement) []*note.Note {
+	if len(r) == 0 {
+		return []*note.Note{}
+	}
+	found := note.NewNoteSet(r[0])
+	for i, step := range steps {
+		if i+1 < len(r) {
+			found = refine(found, step.Op, note.NewNoteSet(r[i+1]))
+		}
+	}
+	return found.Notes()
+}
+
+// find returns the notes in the scope that match query, using the options of the search
+func (s savedSearch) find(ctx context.Context, sc searchScope, query string, progress func(*note.Note)) ([]*note.Note, error) {
+	q, err := search.ParseQuery(query, time.Now())
+	if err != nil {
+		// probably a half-typed filter, like after:2023-0
+		return []*note.Note{}, nil
+	}
+	q.Fuzzy = s.Fuzzy
+	q.Grammar = sc.grammar
+	found, err := search.Find(ctx, sc.dataDir, sc.journals, q, progress)
+	if err != nil {
+		return nil, err
+	}
+	search.Rank(found, q, sc.vocabularies)
+	search.Sort(found, sc.order)
+	return found, nil
+}
+
+// refine combines the notes found by a query with the found notes
+func refine(found *note.NoteSet, op string, notes *note.NoteSet) *note.NoteSet {
+	switch op {
+	case opWiden:
+		return found.Union(notes)
+	case opNarrow:
+		return found.Intersection(notes)
+	case opExclude:
+		return found.Difference(notes)
+	}
+	return found
+}
+
+// opSymbol is how a refinement is shown in the breadcrumbs above the found list
+func opSymbol(op string) string {
+	switch op {
+	case opWiden:
+		return "or"
+	case opNarrow:
+		return "and"
+	case opExclude:
+		return "not"
+	}
+	return op
+}
+
+// removeStep takes the ith refinement out of the current search,
+// working out the found notes again from the notes each of the others found
+func (u *ui) removeStep(i int) {
+	theSearch.Name = "" // no longer the same as any saved search
+	theSearch.Steps = append(theSearch.Steps[:i:i], theSearch.Steps[i+1:]...)
+	theFoundLock.Lock()
+	r := theResults
+	theFoundLock.Unlock()
+	if i+1 < len(r) {
+		r = append(r[:i+1:i+1], r[i+2:]...)
+	}
+	u.setResults(r)
+	u.postFind()
+}
+
+// showBreadcrumbs shows the query and refinements of the current search, s, above the found list,
+// a line each, with the number of notes each found and a button to take away each refinement
+func (u *ui) showBreadcrumbs(s savedSearch) {
+	u.breadcrumbs.Objects = nil
+	if len(s.Steps) == 0 {
+		u.breadcrumbs.Hide()
+		return
+	}
+	theFoundLock.Lock()
+	r := theResults
+	theFoundLock.Unlock()
+	count := func(i int) string {
+		if i < len(r) {
+			return fmt.Sprintf(" (%d)", len(r[i]))
+		}
+		return ""
+	}
+	u.breadcrumbs.Add(widget.NewLabel(s.Query + count(0)))
+	for i, step := range s.Steps {
+		i := i
+		b := widget.NewButtonWithIcon(opSymbol(step.Op)+" "+step.Query+count(i+1), theme.ContentRemoveIcon(), func() {
+			u.removeStep(i)
+		})
+		b.Importance = widget.LowImportance
+		u.breadcrumbs.Add(b)
+	}
+	u.breadcrumbs.Show()
+	u.breadcrumbs.Refresh()
+}
+
+// savedSearchIndex returns the index of the named saved search, or -1
+func savedSearchIndex(name string) int {
+	for i, s := range theSettings.SavedSearches {
+		if s.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// promptUserToSaveSearch saves the current search under a new name, or replaces an existing one
+func (u *ui) promptUserToSaveSearch() {
+	if theSearch.Query == "" {
+		return
+	}
+	var names []string
+	for _, s := range theSettings.SavedSearches {
+		names = append(names, s.Name)
+	}
+	fynex.ShowListEntryPopUp2(u.mainWindow.Canvas(), "Save Search", names, func(str string) {
+		if str == "" {
+			return
+		}
+		s := theSearch
+		s.Name = str
+		if i := savedSearchIndex(str); i >= 0 {
+			theSettings.SavedSearches[i] = s
+		} else {
+			theSettings.SavedSearches = append(theSettings.SavedSearches, s)
+			sort.Slice(theSettings.SavedSearches, func(i, j int) bool {
+				return theSettings.SavedSearches[i].Name < theSettings.SavedSearches[j].Name
+			})
+		}
+		saveSettings()
+		theSearch = s
+		u.refreshSavedSearches()
+	})
+}
+
+// refreshSavedSearches reruns the saved searches in the background, so they behave like live folders,
+// cancelling any rerun already under way; if the found notes came from a saved search,
+// and haven't been replaced since, they are replaced by its new results
+func (u *ui) refreshSavedSearches() {
+	if savedSearchCancel != nil {
+		savedSearchCancel()
+	}
+	saved := append([]savedSearch{}, theSettings.SavedSearches...)
+	// show the saved searches as they are now; their counts follow when they have been rerun
+	theFoundLock.Lock()
+	theSavedShown = saved
+	theFoundLock.Unlock()
+	u.savedList.Refresh()
+	scopes := make([]searchScope, len(saved))
+	var scopeErr error
+	for i, s := range saved {
+		sc, err := s.scope()
+		if err != nil {
+			scopeErr = err
+			continue // the scope has no journals, so the search is skipped
+		}
+		scopes[i] = sc
+	}
+	if scopeErr != nil {
+		dialog.ShowError(scopeErr, u.mainWindow)
+	}
+	current := theSearch
+	theFoundLock.Lock()
+	serial := theFoundSerial
+	theFoundLock.Unlock()
+	ctx, cancel := context.WithCancel(context.Background())
+	savedSearchCancel = cancel
+	go func() {
+		counts := make(map[string]int, len(saved))
+		var found []*note.Note
+		var r results
+		for i, s := range saved {
+			if scopes[i].journals == nil {
+				continue
+			}
+			sr, err := s.runContext(ctx, scopes[i], nil)
+			if err != nil {
+				return // cancelled, and a newer rerun has taken over
+			}
+			counts[s.Name] = len(sr.combine(s.Steps))
+			if current.Name != "" && s.Name == current.Name {
+				r = sr
+				found = sr.combine(s.Steps)
+				search.Sort(found, scopes[i].order)
+			}
+		}
+		theFoundLock.Lock()
+		if ctx.Err() != nil {
+			theFoundLock.Unlock()
+			return
+		}
+		theSavedCounts = counts
+		theFoundLock.Unlock()
+		// refreshing the list reads theSavedShown and theSavedCounts, so must be done without holding the lock
+		u.savedList.Refresh()
+		if r != nil && u.publishFound(ctx, serial, r, found) {
+			u.showBreadcrumbs(current)
+		}
+	}()
+}
+
+func (u *ui) buildSavedList() *widget.List {
+	list := widget.NewList(
+		func() int {
+			theFoundLock.Lock()
+			defer theFoundLock.Unlock()
+			return len(theSavedShown)
+		},
+		func() fyne.CanvasObject {
+			name := widget.NewLabel("")
+			del := widget.NewButtonWithIcon("", theme.DeleteIcon(), nil)
+			del.Importance = widget.LowImportance
+			return container.New(layout.NewBorderLayout(nil, nil, nil, del), del, name)
+		},
+		func(id widget.ListItemID, obj fyne.CanvasObject) {
+			theFoundLock.Lock()
+			if id >= len(theSavedShown) {
+				theFoundLock.Unlock()
+				return
+			}
+			s := theSavedShown[id]
+			count, ok := theSavedCounts[s.Name]
+			theFoundLock.Unlock()
+			c := obj.(*fyne.Container)
+			if ok {
+				c.Objects[1].(*widget.Label).SetText(fmt.Sprintf("%s (%d)", s.Name, count))
+			} else {
+				c.Objects[1].(*widget.Label).SetText(s.Name)
+			}
+			c.Objects[0].(*widget.Button).OnTapped = func() {
+				if i := savedSearchIndex(s.Name); i >= 0 {
+					theSettings.SavedSearches = append(theSettings.SavedSearches[:i], theSettings.SavedSearches[i+1:]...)
+					saveSettings()
+					u.refreshSavedSearches()
+				}
+			}
+		},
+	)
+	list.OnSelected = func(id widget.ListItemID) {
+		list.UnselectAll()
+		theFoundLock.Lock()
+		if id >= len(theSavedShown) {
+			theFoundLock.Unlock()
+			return
+		}
+		theSearch = theSavedShown[id]
+		theFoundLock.Unlock()
+		r, err := theSearch.run()
+		if err != nil {
+			dialog.ShowError(err, u.mainWindow)
+			return
+		}
+		u.setResults(r)
+		u.sideTabs.SelectIndex(0)
+		u.postFind()
+	}
+	return list
+}
+
+// setResults replaces the found notes with the combination of the results of the current search,
+// cancelling any live search that would overwrite them
+func (u *ui) setResults(r results) {
+	u.cancelLiveSearch()
+	found := r.combine(theSearch.Steps)
+	search.Sort(found, theSortOrder)
+	theFoundLock.Lock()
+	theResults = r
+	theFound = found
+	theFoundSerial++
+	theFoundLock.Unlock()
+	u.showFoundCount(len(found))
+	u.showBreadcrumbs(theSearch)
+	u.foundList.Refresh()
+}
+
+func (u *ui) showFoundCount(count int) {
+	switch count {
+	case 0:
+		u.foundCount.SetText("")
+	case 1:
+		u.foundCount.SetText("1 note")
+	default:
+		u.foundCount.SetText(fmt.Sprintf("%d notes", count))
+	}
+}
+
+// liveSearch runs a search in the background once the user has stopped typing,
+// cancelling any search already under way, and streaming the notes it finds into the found list
+func (u *ui) liveSearch(s savedSearch) {
+	theSearch = s
+	u.setResults(nil)
+	u.foundList.UnselectAll()
+	if len(s.Query) < 2 {
+		return
+	}
+	sc, err := s.scope()
+	if err != nil {
+		dialog.ShowError(err, u.mainWindow)
+		return
+	}
+	theFoundLock.Lock()
+	serial := theFoundSerial
+	theFoundLock.Unlock()
+	ctx, cancel := context.WithCancel(context.Background())
+	liveSearchCancel = cancel
+	liveSearchTimer = time.AfterFunc(liveSearchDelay, func() {
+		u.runLiveSearch(ctx, serial, s, sc)
+	})
+}
+
+func (u *ui) cancelLiveSearch() {
+	if liveSearchTimer != nil {
+		liveSearchTimer.Stop()
+		liveSearchTimer = nil
+	}
+	if liveSearchCancel != nil {
+		// with the lock held, so a live search can't be showing the busy bar at the same time
+		theFoundLock.Lock()
+		liveSearchCancel()
+		theFoundLock.Unlock()
+		liveSearchCancel = nil
+	}
+	u.searchBusy.Stop()
+	u.searchBusy.Hide()
+}
+
+// showSearchBusy shows or hides the busy bar for a live search, unless it has been cancelled,
+// in which case the bar belongs to whichever search has taken over
+func (u *ui) showSearchBusy(ctx context.Context, busy bool) {
+	theFoundLock.Lock()
+	defer theFoundLock.Unlock()
+	if ctx.Err() != nil {
+		return
+	}
+	if busy {
+		u.searchBusy.Show()
+		u.searchBusy.Start()
+	} else {
+		u.searchBusy.Stop()
+		u.searchBusy.Hide()
+	}
+}
+
+// runLiveSearch is called on its own goroutine
+func (u *ui) runLiveSearch(ctx context.Context, serial int, s savedSearch, sc searchScope) {
+	u.showSearchBusy(ctx, true)
+	defer u.showSearchBusy(ctx, false)
+	var partial []*note.Note
+	var published time.Time
+	r, err := s.runContext(ctx, sc, func(n *note.Note) {
+		partial = append(partial, n)
+		// don't swamp the list with refreshes
+		if time.Since(published) > 100*time.Millisecond {
+			published = time.Now()
+			found := append([]*note.Note{}, partial...)
+			u.publishFound(ctx, serial, results{found}, found)
+		}
+	})
+	if err != nil {
+		return // cancelled, and a newer search has taken over, or the notes couldn't be read
+	}
+	u.publishFound(ctx, serial, r, r[0])
+}
+
+// publishFound replaces the found notes with those found by a search running in the background,
+// unless it has been cancelled, or the found notes have been replaced since it started, when serial was theFoundSerial.
+// It reports whether it replaced them.
+func (u *ui) publishFound(ctx context.Context, serial int, r results, found []*note.Note) bool {
+	theFoundLock.Lock()
+	if ctx.Err() != nil || serial != theFoundSerial {
+		theFoundLock.Unlock()
+		return false
+	}
+	theResults = r
+	theFound = found
+	theFoundLock.Unlock()
+	// refreshing the list reads theFound, so must be done without holding the lock
+	u.showFoundCount(len(found))
+	u.foundList.Refresh()
+	return true
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"encoding/json"
+	"log"
+	"os"
+	"path"
+
+	"oddstream.cj/tags"
+)
+
+// settingsFilename is the name of the file holding a journal's settings, in the journal's directory;
+// it is hidden so that searches skip it
+const settingsFilename = ".settings.json"
+
+// settings are the things remembered for each journal
+type settings struct {
+	SavedSearches []savedSearch       `json:"savedSearches,omitempty"`
+	History       []historyEntry      `json:"history,omitempty"`      // most recent first
+	NoEmojiTags   bool                `json:"noEmojiTags,omitempty"`  // emoji aren't allowed in hashtags
+	TagColors     map[string]string   `json:"tagColors,omitempty"`    // tag to color, like "#holiday": "green" or "#ff8000"
+	NudgeTime     string              `json:"nudgeTime,omitempty"`    // time of day, like 20:00, to be nudged if today's note is empty
+	Recurred      map[string][]string `json:"recurred,omitempty"`     // day, like 2023-07-04, to the recurring tasks added to its note
+	TagPanelLeft  bool                `json:"tagPanelLeft,omitempty"` // the tag panel is docked on the left of the note, not the right
+}
+
+var theSettings settings // settings of the current journal
+
+var theGrammar tags.Grammar // what counts as a hashtag in the current journal
+
+func loadSettings() {
+	theSettings = settings{}
+	// it's ok if there are no settings yet
+	if bytes, err := os.ReadFile(path.Join(theDirectory, settingsFilename)); err == nil {
+		if err := json.Unmarshal(bytes, &theSettings); err != nil {
+			log.Printf("couldn't read settings for %s: %s\n", theJournalDir, err)
+		}
+	}
+	theGrammar = tags.Grammar{NoEmoji: theSettings.NoEmojiTags}
+	theTagColors = parseTagColors(theSettings.TagColors)
+}
+
+func saveSettings() {
+	bytes, err := json.MarshalIndent(theSettings, "", "\t")
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := os.MkdirAll(theDirectory, 0755); err != nil {
+		log.Fatal(err)
+	}
+	if err := os.WriteFile(path.Join(theDirectory, settingsFilename), bytes, 0644); err != nil {
+		log.Printf("couldn't save settings for %s: %s\n", theJournalDir, err)
+	}
+}
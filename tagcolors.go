@@ -0,0 +1,96 @@
+package main
+
+import (
+	"image/color"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"oddstream.cj/fynex"
+	"oddstream.cj/note"
+	"oddstream.cj/tags"
+)
+
+// tintAlpha is how strongly a tag's color tints the note and calendar, so the text can still be read
+const tintAlpha = 64
+
+var theTagColors map[string]color.NRGBA // the colors of tags in the current journal, keyed by tag key
+
+// namedColors are the colors that can be given by name in the settings, rather than as #rrggbb
+var namedColors = map[string]color.NRGBA{
+	"red":    {R: 0xe5, G: 0x39, B: 0x35, A: 0xff},
+	"orange": {R: 0xfb, G: 0x8c, B: 0x00, A: 0xff},
+	"yellow": {R: 0xfd, G: 0xd8, B: 0x35, A: 0xff},
+	"green":  {R: 0x43, G: 0xa0, B: 0x47, A: 0xff},
+	"blue":   {R: 0x1e, G: 0x88, B: 0xe5, A: 0xff},
+	"purple": {R: 0x8e, G: 0x24, B: 0xaa, A: 0xff},
+	"pink":   {R: 0xd8, G: 0x1b, B: 0x60, A: 0xff},
+	"brown":  {R: 0x6d, G: 0x4c, B: 0x41, A: 0xff},
+	"grey":   {R: 0x75, G: 0x75, B: 0x75, A: 0xff},
+	"gray":   {R: 0x75, G: 0x75, B: 0x75, A: 0xff},
+}
+
+// parseTagColors reads the tag colors from the settings, skipping any that don't make sense
+func parseTagColors(settings map[string]string) map[string]color.NRGBA {
+	colors := make(map[string]color.NRGBA)
+	for name, value := range settings {
+		if !strings.HasPrefix(name, "#") {
+			name = "#" + name
+		}
+		if !theGrammar.Valid(name) {
+			log.Printf("tagColors: %q is not a hashtag\n", name)
+			continue
+		}
+		c, ok := parseColor(value)
+		if !ok {
+			log.Printf("tagColors: %q is not a color\n", value)
+			continue
+		}
+		colors[tags.Key(name)] = c
+	}
+	return colors
+}
+
+// parseColor reads a color name, or #rrggbb
+func parseColor(value string) (color.NRGBA, bool) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	if c, ok := namedColors[value]; ok {
+		return c, true
+	}
+	if len(value) != 7 || value[0] != '#' {
+		return color.NRGBA{}, false
+	}
+	rgb, err := strconv.ParseUint(value[1:], 16, 32)
+	if err != nil {
+		return color.NRGBA{}, false
+	}
+	return color.NRGBA{R: uint8(rgb >> 16), G: uint8(rgb >> 8), B: uint8(rgb), A: 0xff}, true
+}
+
+// tagTint returns the tint for the first of the tags with a color, or nil if none of them have one
+func tagTint(keys []string) color.Color {
+	for _, key := range keys {
+		if c, ok := theTagColors[key]; ok {
+			c.A = tintAlpha
+			return c
+		}
+	}
+	return nil
+}
+
+// tintNote tints the background of the note being edited with the color of its tags
+func (u *ui) tintNote() {
+	u.theme.SetNoteColor(tagTint(theGrammar.Find(theNote.Text)))
+	u.noteTint.FillColor = u.theme.Color(fynex.ColorNameNote, fyne.CurrentApp().Settings().ThemeVariant())
+	u.noteTint.Refresh()
+}
+
+// calendarTint returns the color of the tags used on a day, for the calendar
+func calendarTint(t time.Time) color.Color {
+	if len(theTagColors) == 0 || theCatalogue == nil {
+		return nil
+	}
+	return tagTint(theCatalogue.Keys(note.NewNote(theJournalDir, theDirectory, t).Pathname))
+}
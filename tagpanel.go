@@ -0,0 +1,212 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/widget"
+	"oddstream.cj/tags"
+)
+
+// sparklinePeriods is how many bars there are in a tag's sparkline, each an equal share of the journal's life
+const sparklinePeriods = 12
+
+var (
+	theCatalogue   *tags.Catalogue   // the tags used in the current journal
+	theTagList     []tags.Tag        // every tag, as shown in the tag panel
+	theTagSparks   map[string]string // the sparkline of each tag in theTagList, by key
+	theCoTags      []tags.Tag        // the tags used with theSelectedTag
+	theSelectedTag string            // the key of the tag selected in the tag panel, if any
+)
+
+// buildTagPanel makes the panel beside the note listing every tag, hidden until the tag button is tapped
+func (u *ui) buildTagPanel() *fyne.Container {
+	u.tagList = widget.NewList(
+		func() int {
+			return len(theTagList)
+		},
+		newTagRow,
+		func(id widget.ListItemID, obj fyne.CanvasObject) {
+			t := theTagList[id]
+			updateTagRow(obj, t, theTagSparks[t.Key])
+		},
+	)
+	u.tagList.OnSelected = func(id widget.ListItemID) {
+		theSelectedTag = theTagList[id].Key
+		theCoTags = theCatalogue.CoOccurring(theSelectedTag)
+		u.coTagLabel.SetText("Used with " + theTagList[id].Name)
+		u.coTagList.UnselectAll()
+		u.coTagList.Refresh()
+	}
+	u.coTagLabel = widget.NewLabel("")
+	u.coTagList = widget.NewList(
+		func() int {
+			return len(theCoTags)
+		},
+		func() fyne.CanvasObject {
+			return widget.NewLabel("")
+		},
+		func(id widget.ListItemID, obj fyne.CanvasObject) {
+			t := theCoTags[id]
+			obj.(*widget.Label).SetText(fmt.Sprintf("%s (%d)", t.Name, t.Count))
+		},
+	)
+	u.coTagList.OnSelected = func(id widget.ListItemID) {
+		u.selectTag(theCoTags[id].Key)
+	}
+	search := widget.NewButton("Search", func() {
+		if t, ok := selectedTag(); ok {
+			u.searchForTag(t.Name)
+		}
+	})
+	rename := widget.NewButton("Rename", func() {
+		u.renameTags()
+	})
+	u.tagDock = widget.NewButton("", func() {
+		theSettings.TagPanelLeft = !theSettings.TagPanelLeft
+		saveSettings()
+		u.dockTagPanel()
+	})
+	buttons := container.New(layout.NewGridLayout(3), search, rename, u.tagDock)
+	coTags := container.New(layout.NewBorderLayout(u.coTagLabel, buttons, nil, nil), u.coTagLabel, buttons, u.coTagList)
+	split := container.NewVSplit(u.tagList, coTags)
+	split.Offset = 0.6
+	width := canvas.NewRectangle(nil) // keeps the panel wide enough for the sparklines
+	width.SetMinSize(fyne.NewSize(280, 0))
+	u.tagPanel = container.New(layout.NewMaxLayout(), width, split)
+	u.tagPanel.Hide()
+	return u.tagPanel
+}
+
+func newTagRow() fyne.CanvasObject {
+	name := widget.NewLabel("")
+	count := widget.NewLabel("")
+	top := container.New(layout.NewBorderLayout(nil, nil, nil, count), count, name)
+	return container.New(layout.NewVBoxLayout(), top, widget.NewLabel(""))
+}
+
+func updateTagRow(obj fyne.CanvasObject, t tags.Tag, spark string) {
+	c := obj.(*fyne.Container)
+	top := c.Objects[0].(*fyne.Container)
+	top.Objects[0].(*widget.Label).SetText(fmt.Sprint(t.Count))
+	top.Objects[1].(*widget.Label).SetText(t.Name)
+	detail := spark
+	if !t.First.IsZero() {
+		detail += " " + t.First.Format("2 Jan 2006")
+		if !t.Last.Equal(t.First) {
+			detail += " – " + t.Last.Format("2 Jan 2006")
+		}
+	}
+	c.Objects[1].(*widget.Label).SetText(detail)
+}
+
+// dockTagPanel puts the tag panel on the side of the note the journal's settings say, the right unless told otherwise
+func (u *ui) dockTagPanel() {
+	top := u.mainPanel.Objects[0]
+	if theSettings.TagPanelLeft {
+		u.mainPanel.Layout = layout.NewBorderLayout(top, nil, u.tagPanel, nil)
+		u.tagDock.SetText("Dock right")
+	} else {
+		u.mainPanel.Layout = layout.NewBorderLayout(top, nil, nil, u.tagPanel)
+		u.tagDock.SetText("Dock left")
+	}
+	u.mainPanel.Refresh()
+}
+
+// toggleTagPanel shows or hides the tag panel
+func (u *ui) toggleTagPanel() {
+	if u.tagPanel.Visible() {
+		u.tagPanel.Hide()
+		return
+	}
+	u.saveNote()
+	u.refreshTags()
+	u.tagPanel.Show()
+}
+
+// selectTag selects the tag with the given key in the tag panel, scrolling to it
+func (u *ui) selectTag(key string) {
+	for i, t := range theTagList {
+		if t.Key == key {
+			u.tagList.Select(i)
+			u.tagList.ScrollTo(i)
+			return
+		}
+	}
+}
+
+// searchForTag finds the notes using a tag
+func (u *ui) searchForTag(name string) {
+	u.saveNote()
+	u.searchEntry.SetText("")
+	theSearch = newSearch(name)
+	r, err := theSearch.run()
+	if err != nil {
+		dialog.ShowError(err, u.mainWindow)
+		return
+	}
+	u.setResults(r)
+	u.sideTabs.SelectIndex(0)
+	u.postFind()
+}
+
+// showTagUsage opens the tag panel at the tag with the given key
+func (u *ui) showTagUsage(key string) {
+	if !u.tagPanel.Visible() {
+		u.toggleTagPanel()
+	}
+	u.selectTag(key)
+}
+
+// selectedTag returns the tag selected in the tag panel
+func selectedTag() (tags.Tag, bool) {
+	for _, t := range theTagList {
+		if t.Key == theSelectedTag {
+			return t, true
+		}
+	}
+	return tags.Tag{}, false
+}
+
+// tagsChanged shows the tags as they are after the notes have been changed
+func (u *ui) tagsChanged() {
+	if u.tagPanel.Visible() {
+		u.refreshTags()
+	}
+}
+
+// tagCompletions returns the tags to offer for completing a hashtag being typed in the note
+func tagCompletions(prefix string) []string {
+	var names []string
+	for _, t := range theCatalogue.Complete(prefix, time.Now()) {
+		if t.Key != tags.Key(prefix) && len(names) < maxSuggestions {
+			names = append(names, t.Name)
+		}
+	}
+	return names
+}
+
+// refreshTags shows the tags in the catalogue, keeping the selected tag selected if it is still used
+func (u *ui) refreshTags() {
+	theTagList = theCatalogue.Tags()
+	first, last := theCatalogue.Span()
+	theTagSparks = make(map[string]string, len(theTagList))
+	for key, counts := range theCatalogue.Usage(first, last, sparklinePeriods) {
+		theTagSparks[key] = tags.Sparkline(counts)
+	}
+	selected := theSelectedTag
+	theSelectedTag = ""
+	theCoTags = nil
+	u.coTagLabel.SetText("")
+	u.tagList.UnselectAll()
+	u.tagList.Refresh()
+	u.coTagList.Refresh()
+	if selected != "" {
+		u.selectTag(selected)
+	}
+}
@@ -0,0 +1,167 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/widget"
+	"oddstream.cj/note"
+	"oddstream.cj/tags"
+)
+
+// tagUse is a note that uses some tags, and how many times
+type tagUse struct {
+	n     *note.Note
+	count int
+}
+
+// renameTags pops up a way to rename the selected tag, or to merge several tags into one,
+// throughout the journal, previewing the notes that would change
+func (u *ui) renameTags() {
+	var pu *widget.PopUp
+	var uses []tagUse
+
+	if theSelectedTag == "" {
+		return
+	}
+	u.saveNote() // so the note being edited is renamed as it is in the editor
+	all := theCatalogue.Tags()
+	checked := map[string]bool{theSelectedTag: true} // keyed by tag key
+	from := func() []string {
+		var keys []string
+		for _, t := range all {
+			if checked[t.Key] {
+				keys = append(keys, t.Key)
+			}
+		}
+		return keys
+	}
+
+	nameEntry := widget.NewEntry()
+	if t, ok := selectedTag(); ok {
+		nameEntry.SetText(t.Name)
+	}
+	summary := widget.NewLabel("")
+
+	var previewList *widget.List
+	preview := func() {
+		uses = nil
+		var total int
+		for _, pathname := range theCatalogue.Notes(from()) {
+			n := note.NewNote(theJournalDir, theDirectory, pathname)
+			n.Load()
+			if _, count := theGrammar.Rename(n.Text, from(), ""); count > 0 {
+				uses = append(uses, tagUse{n: n, count: count})
+				total += count
+			}
+		}
+		summary.SetText(fmt.Sprintf("%d uses in %d notes", total, len(uses)))
+		previewList.Refresh()
+	}
+
+	tagList := widget.NewList(
+		func() int {
+			return len(all)
+		},
+		func() fyne.CanvasObject {
+			return widget.NewCheck("", nil)
+		},
+		func(id widget.ListItemID, obj fyne.CanvasObject) {
+			t := all[id]
+			check := obj.(*widget.Check)
+			check.OnChanged = nil // don't call back while setting up the row
+			check.Text = fmt.Sprintf("%s (%d)", t.Name, t.Count)
+			check.SetChecked(checked[t.Key])
+			check.OnChanged = func(b bool) {
+				checked[t.Key] = b
+				preview()
+			}
+		},
+	)
+	previewList = widget.NewList(
+		func() int {
+			return len(uses)
+		},
+		func() fyne.CanvasObject {
+			return widget.NewLabel("")
+		},
+		func(id widget.ListItemID, obj fyne.CanvasObject) {
+			n := uses[id].n
+			title := noteTitle(n)
+			if uses[id].count == 1 {
+				obj.(*widget.Label).SetText(title)
+			} else {
+				obj.(*widget.Label).SetText(fmt.Sprintf("%s, %d times", title, uses[id].count))
+			}
+		},
+	)
+
+	rename := widget.NewButton("Rename", func() {
+		to := strings.TrimSpace(nameEntry.Text)
+		if !strings.HasPrefix(to, "#") {
+			to = "#" + to
+		}
+		if !theGrammar.Valid(to) {
+			dialog.ShowError(fmt.Errorf("%q is not a hashtag", to), u.mainWindow)
+			return
+		}
+		keys := from()
+		if len(keys) == 0 {
+			return
+		}
+		pu.Hide()
+		batch, err := renameTagsInNotes(keys, to)
+		if len(batch) > 0 {
+			theLastReplace = batch
+			u.afterReplace(batch)
+		}
+		if err != nil {
+			dialog.ShowError(err, u.mainWindow)
+		}
+		theSelectedTag = tags.Key(to)
+		u.refreshTags()
+		dialog.ShowInformation("Rename Tags",
+			fmt.Sprintf("%d notes changed; Undo last replace in Find and Replace puts them back", len(batch)), u.mainWindow)
+	})
+	cancel := widget.NewButton("Cancel", func() {
+		pu.Hide()
+	})
+
+	top := container.New(layout.NewVBoxLayout(), widget.NewLabel("Rename or merge the checked tags into"), nameEntry)
+	bottom := container.New(layout.NewVBoxLayout(), summary, container.New(layout.NewGridLayout(2), rename, cancel))
+	lists := container.NewHSplit(tagList, previewList)
+	content := container.New(layout.NewBorderLayout(top, bottom, nil, nil), top, bottom, lists)
+	pu = widget.NewModalPopUp(content, u.mainWindow.Canvas())
+	pu.Resize(fyne.NewSize(560, 420))
+	pu.Show()
+	preview()
+}
+
+// renameTagsInNotes replaces the tags with the keys in from with to in every note using them,
+// rereading each note as it goes so nothing written since the preview is lost,
+// returning what was changed so it can be undone
+func renameTagsInNotes(from []string, to string) ([]replaced, error) {
+	var batch []replaced
+	var firstErr error
+	for _, pathname := range theCatalogue.Notes(from) {
+		n := note.NewNote(theJournalDir, theDirectory, pathname)
+		n.Load()
+		before := n.Text
+		after, count := theGrammar.Rename(before, from, to)
+		if count == 0 {
+			continue
+		}
+		changed, err := n.SaveIfDirty(after)
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+		if changed {
+			batch = append(batch, replaced{pathname: pathname, before: before, after: after})
+		}
+	}
+	return batch, firstErr
+}
@@ -0,0 +1,99 @@
+package tags
+
+import (
+	"regexp"
+	"strings"
+
+	"oddstream.cj/note"
+	"oddstream.cj/util"
+)
+
+// Scoping rule: a tag normally applies to the whole of the day's note, but tags at the start
+// of a paragraph, before any other words, apply to just that paragraph, and tags at the start
+// of a list item apply to just that item:
+//
+//	#cats
+//	This paragraph is about cats, and so are the items below it.
+//	- #food tuna, and this item is about food
+//	[ ] #shopping only this item needs shopping for
+//
+// A list item starts with -, *, +, a number followed by . or ), or a [ ] box,
+// and carries on until the next item or the end of the paragraph.
+
+var listItemPattern = regexp.MustCompile(`^\s*([-*+]|\d+[.)]|\[[ xX-]\])\s+`)
+
+// Block is a paragraph or a list item, with the tags scoped to it
+type Block struct {
+	note.Paragraph
+	Tags  []string // keys
+	Items []Block  // the list items in a paragraph
+}
+
+// Blocks parses text into paragraphs, each with its list items
+func (g Grammar) Blocks(text string) []Block {
+	var blocks []Block
+	for _, p := range note.Paragraphs(text) {
+		b := Block{Paragraph: p, Tags: g.leadingTags(p.Text)}
+		lines := strings.Split(p.Text, "\n")
+		for i, line := range lines {
+			if listItemPattern.MatchString(line) {
+				b.Items = append(b.Items, Block{Paragraph: note.Paragraph{First: p.First + i, Last: p.First + i, Text: line}})
+			} else if n := len(b.Items); n > 0 {
+				item := &b.Items[n-1]
+				item.Last = p.First + i
+				item.Text += "\n" + line
+			}
+		}
+		for i := range b.Items {
+			b.Items[i].Tags = g.leadingTags(listItemPattern.ReplaceAllString(b.Items[i].Text, ""))
+		}
+		blocks = append(blocks, b)
+	}
+	return blocks
+}
+
+// leadingTags returns the keys of the tags at the start of text, before any other words
+func (g Grammar) leadingTags(text string) []string {
+	var found []string
+	for _, word := range strings.Fields(text) {
+		if !g.Valid(word) {
+			break
+		}
+		found = append(found, Key(word))
+	}
+	return found
+}
+
+// Passages returns the parts of text the tag with the given key applies to: the paragraphs and list items
+// it is scoped to, or the whole text as a single passage if the tag is used anywhere else
+func (g Grammar) Passages(text string, key string) []note.Paragraph {
+	var passages []note.Paragraph
+	for _, b := range g.Blocks(text) {
+		if util.Contains(b.Tags, key) {
+			passages = append(passages, b.Paragraph)
+			continue
+		}
+		for _, item := range b.Items {
+			if util.Contains(item.Tags, key) {
+				passages = append(passages, item.Paragraph)
+			}
+		}
+	}
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		if util.Contains(g.Find(line), key) && !covered(passages, i+1) {
+			// used outside a scope, so it applies to the whole day
+			return []note.Paragraph{{First: 1, Last: len(lines), Text: strings.TrimRight(text, " \t\r\n")}}
+		}
+	}
+	return passages
+}
+
+func covered(passages []note.Paragraph, line int) bool {
+	for _, p := range passages {
+		if p.Contains(line) {
+			return true
+		}
+	}
+	return false
+}
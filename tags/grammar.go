@@ -0,0 +1,141 @@
+package tags
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/cases"
+	"golang.org/x/text/unicode/norm"
+)
+
+// The hashtag grammar:
+//
+//	tag     = "#" body
+//	body    = one or more of: a Unicode letter, mark or digit, "_", "-", "/", or an emoji
+//
+// with these rules to avoid false positives:
+//
+//   - the # must start a line, or follow a space or one of ( [ { " ' `
+//     so URL fragments like example.com/page#part, C#, and ## headings are not tags
+//   - the body must have at least one letter (or emoji) in it, so #1 and #42 are issue numbers, not tags
+//   - a - or / at the end of the body is punctuation, not part of the tag
+//   - a markdown heading needs a space after the #, so "# Heading" is not a tag either
+//   - an emoji is a character shown as an emoji by default, or a symbol followed by the emoji variation selector,
+//     so #🐈 and #❤️ are tags, but #©, #°C and #✓ are not
+//
+// So #café, #日本, #to-do, #snake_case, #work/meetings and #🐈 are all tags.
+// Tags are compared by their key, which ignores case and how accented letters were typed,
+// so #Café and #café are the same tag, but the tag is shown as it was written.
+
+// Grammar is the hashtag grammar of a journal; its zero value allows emoji in tags
+type Grammar struct {
+	NoEmoji bool // emoji can't be used in tags, as in #🐈 or #read📚
+}
+
+// Token is a hashtag found in some text
+type Token struct {
+	Start, End int    // byte offsets of the tag, including the #
+	Name       string // as written, eg #Café
+	Key        string // for comparing, eg #café
+}
+
+// Key returns the key of a tag, for comparing it with others
+func Key(name string) string {
+	return cases.Fold().String(norm.NFC.String(name))
+}
+
+// IsTagRune reports whether r can be part of the body of a tag
+func (g Grammar) IsTagRune(r rune) bool {
+	switch {
+	case unicode.IsLetter(r), unicode.IsDigit(r), unicode.Is(unicode.M, r):
+		return true
+	case r == '_', r == '-', r == '/':
+		return true
+	}
+	return !g.NoEmoji && isEmoji(r)
+}
+
+const (
+	zeroWidthJoiner = 0x200D // joins emoji, as in 👩‍💻
+	emojiVariation  = 0xFE0F // asks for a symbol to be shown as an emoji, as in ❤️
+)
+
+// isEmoji reports whether r is an emoji, or part of one; skin tones are emoji in their own right
+func isEmoji(r rune) bool {
+	return unicode.Is(emojiPresentation, r) || r == zeroWidthJoiner || r == emojiVariation
+}
+
+// Tokenize returns the hashtags in text, in order
+func (g Grammar) Tokenize(text string) []Token {
+	var tokens []Token
+	for i := 0; i < len(text); {
+		j := strings.IndexByte(text[i:], '#')
+		if j < 0 {
+			break
+		}
+		start := i + j
+		i = start + 1
+		if start > 0 {
+			prev, _ := utf8.DecodeLastRuneInString(text[:start])
+			if !unicode.IsSpace(prev) && !strings.ContainsRune("([{\"'`", prev) {
+				continue
+			}
+		}
+		end := i
+		for end < len(text) {
+			r, size := utf8.DecodeRuneInString(text[end:])
+			if !g.IsTagRune(r) {
+				next, n := utf8.DecodeRuneInString(text[end+size:])
+				if !g.NoEmoji && next == emojiVariation && unicode.Is(unicode.So, r) {
+					end += size + n
+					continue
+				}
+				break
+			}
+			end += size
+		}
+		for end > i && (text[end-1] == '-' || text[end-1] == '/') {
+			end--
+		}
+		if !g.hasLetter(text[i:end]) {
+			continue
+		}
+		name := text[start:end]
+		tokens = append(tokens, Token{Start: start, End: end, Name: name, Key: Key(name)})
+		i = end
+	}
+	return tokens
+}
+
+// At returns the hashtag in text at byte offset pos, which may be just after the end of the tag
+func (g Grammar) At(text string, pos int) (Token, bool) {
+	start := strings.LastIndexByte(text[:pos], '\n') + 1
+	end := len(text)
+	if i := strings.IndexByte(text[pos:], '\n'); i >= 0 {
+		end = pos + i
+	}
+	for _, t := range g.Tokenize(text[start:end]) {
+		if start+t.Start <= pos && pos <= start+t.End {
+			t.Start += start
+			t.End += start
+			return t, true
+		}
+	}
+	return Token{}, false
+}
+
+func (g Grammar) hasLetter(body string) bool {
+	for _, r := range body {
+		if unicode.IsLetter(r) || (!g.NoEmoji && (unicode.Is(emojiPresentation, r) || r == emojiVariation)) {
+			return true
+		}
+	}
+	return false
+}
+
+// Valid reports whether name is a single whole hashtag, like #meeting
+func (g Grammar) Valid(name string) bool {
+	tokens := g.Tokenize(name)
+	return len(tokens) == 1 && tokens[0].Start == 0 && tokens[0].End == len(name)
+}
@@ -0,0 +1,106 @@
+package tags
+
+import (
+	"testing"
+)
+
+func TestTokenize(t *testing.T) {
+	tests := []struct {
+		text string
+		want []string // the names of the tags found
+	}{
+		{"#meeting", []string{"#meeting"}},
+		{"a #café and #日本", []string{"#café", "#日本"}},
+		{"#to-do #snake_case #work/meetings", []string{"#to-do", "#snake_case", "#work/meetings"}},
+		{"trailing #to-do- and #work/", []string{"#to-do", "#work"}},
+		{"#1 and #42 are issues", nil},
+		{"#2023plans", []string{"#2023plans"}},
+		{"see example.com/page#part", nil},
+		{"I write C# and F#", nil},
+		{"## Heading", nil},
+		{"# Heading", nil},
+		{"(#inside) [#square] \"#quoted\" '#single' `#code`", []string{"#inside", "#square", "#quoted", "#single", "#code"}},
+		{"end.#nope", nil},
+		{"#tag, then more", []string{"#tag"}},
+		{"#🐈 and #read📚", []string{"#🐈", "#read📚"}},
+		{"#👩‍💻 codes", []string{"#👩‍💻"}}, // zero width joiner
+		{"#👍🏽 thumbs", []string{"#👍🏽"}},  // skin tone
+		{"#🇬🇧 flag", []string{"#🇬🇧"}},    // regional indicators
+		{"#❤️ and #❤", []string{"#❤️"}},  // text symbols need the emoji variation selector
+		{"x #© y", nil},
+		{"#°C is hot", nil},
+		{"#✓ done", nil},
+		{"#café©", []string{"#café"}},
+	}
+	for _, tt := range tests {
+		var got []string
+		for _, tok := range (Grammar{}).Tokenize(tt.text) {
+			if tok.Name != tt.text[tok.Start:tok.End] {
+				t.Errorf("Tokenize(%q) has %q at %d:%d", tt.text, tok.Name, tok.Start, tok.End)
+			}
+			got = append(got, tok.Name)
+		}
+		if !equalStrings(got, tt.want) {
+			t.Errorf("Tokenize(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestTokenizeNoEmoji(t *testing.T) {
+	g := Grammar{NoEmoji: true}
+	for _, text := range []string{"#🐈", "#❤️"} {
+		if tokens := g.Tokenize(text); len(tokens) != 0 {
+			t.Errorf("Tokenize(%q) = %v without emoji", text, tokens)
+		}
+	}
+	if tokens := g.Tokenize("#read📚"); len(tokens) != 1 || tokens[0].Name != "#read" {
+		t.Errorf("Tokenize(%q) = %v without emoji, want #read", "#read📚", tokens)
+	}
+}
+
+func TestKey(t *testing.T) {
+	tests := []struct {
+		a, b string
+		same bool
+	}{
+		{"#Café", "#café", true},
+		{"#café", "#café", true}, // a combining accent
+		{"#STRASSE", "#straße", true},
+		{"#cafe", "#café", false},
+	}
+	for _, tt := range tests {
+		if same := Key(tt.a) == Key(tt.b); same != tt.same {
+			t.Errorf("Key(%q) == Key(%q) is %v, want %v", tt.a, tt.b, same, tt.same)
+		}
+	}
+}
+
+func TestValid(t *testing.T) {
+	for name, want := range map[string]bool{"#go": true, "#to-do": true, "#🐈": true, "#1": false, "go": false, "#go now": false, "#go-": false} {
+		if got := (Grammar{}).Valid(name); got != want {
+			t.Errorf("Valid(%q) = %v, want %v", name, got, want)
+		}
+	}
+}
+
+func TestAt(t *testing.T) {
+	text := "first line\nsee #cats here"
+	for pos, want := range map[int]string{15: "#cats", 20: "#cats", 19: "#cats", 14: "", 21: "", 3: ""} {
+		tok, ok := Grammar{}.At(text, pos)
+		if ok != (want != "") || tok.Name != want {
+			t.Errorf("At(%d) = %q, %v, want %q", pos, tok.Name, ok, want)
+		}
+	}
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
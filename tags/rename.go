@@ -0,0 +1,41 @@
+package tags
+
+import (
+	"sort"
+	"strings"
+
+	"oddstream.cj/util"
+)
+
+// Rename replaces every use of the tags with the keys in from with the tag to,
+// returning the new text and how many were replaced.
+// Only whole tags are replaced, so renaming #mtg leaves #mtgs alone.
+func (g Grammar) Rename(text string, from []string, to string) (string, int) {
+	var b strings.Builder
+	var count, last int
+	for _, t := range g.Tokenize(text) {
+		if util.Contains(from, t.Key) {
+			b.WriteString(text[last:t.Start])
+			b.WriteString(to)
+			last = t.End
+			count++
+		}
+	}
+	b.WriteString(text[last:])
+	return b.String(), count
+}
+
+// Notes returns the pathnames of the notes using any of the tags with the given keys, in order
+func (c *Catalogue) Notes(keys []string) []string {
+	var pathnames []string
+	for pathname, nt := range c.notes {
+		for _, key := range keys {
+			if util.Contains(nt.keys, key) {
+				pathnames = append(pathnames, pathname)
+				break
+			}
+		}
+	}
+	sort.Strings(pathnames)
+	return pathnames
+}
@@ -0,0 +1,64 @@
+package tags
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"oddstream.cj/note"
+)
+
+func TestRename(t *testing.T) {
+	tests := []struct {
+		text  string
+		from  []string
+		to    string
+		want  string
+		count int
+	}{
+		{"#mtg at ten, not #mtgs", []string{"#mtg"}, "#meeting", "#meeting at ten, not #mtgs", 1},
+		{"#Work and #WORK and #work", []string{"#work"}, "#job", "#job and #job and #job", 3},
+		{"#todo\n#to-do\nplain", []string{"#todo", "#to-do"}, "#tasks", "#tasks\n#tasks\nplain", 2},
+		{"#café and #cafe", []string{Key("#café")}, "#coffee", "#coffee and #cafe", 1},
+		{"a URL#mtg and C# stay", []string{"#mtg"}, "#meeting", "a URL#mtg and C# stay", 0},
+		{"#mtg\r\n", []string{"#mtg"}, "#m", "#m\r\n", 1},
+		{"nothing here", []string{"#mtg"}, "#meeting", "nothing here", 0},
+	}
+	for _, tt := range tests {
+		got, count := Grammar{}.Rename(tt.text, tt.from, tt.to)
+		if got != tt.want || count != tt.count {
+			t.Errorf("Rename(%q, %q, %q) = %q, %d, want %q, %d", tt.text, tt.from, tt.to, got, count, tt.want, tt.count)
+		}
+	}
+}
+
+func TestNotes(t *testing.T) {
+	directory := t.TempDir()
+	for pathname, text := range map[string]string{
+		"2023/07/02.txt": "#b",
+		"2023/07/01.txt": "#A and #c",
+		"2023/07/03.txt": "nothing",
+		"2022/12/31.txt": "#a",
+	} {
+		pathname = filepath.Join(directory, pathname)
+		if err := os.MkdirAll(filepath.Dir(pathname), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(pathname, []byte(text), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	c := NewCatalogue(Grammar{})
+	note.NewIndex("Default", directory, c)
+	got := c.Notes([]string{"#a", "#b"})
+	want := []string{"2022/12/31.txt", "2023/07/01.txt", "2023/07/02.txt"}
+	if len(got) != len(want) {
+		t.Fatalf("Notes found %q, want %q", got, want)
+	}
+	for i := range want {
+		if got[i] != filepath.Join(directory, want[i]) {
+			t.Errorf("Notes found %q, want %q", got, want)
+			break
+		}
+	}
+}
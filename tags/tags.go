@@ -0,0 +1,217 @@
+package tags
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"oddstream.cj/note"
+	"oddstream.cj/util"
+)
+
+// Find returns the keys of the hashtags in text, without duplicates, in the order they first appear
+func (g Grammar) Find(text string) []string {
+	keys, _ := g.find(text)
+	return keys
+}
+
+// find returns the keys of the hashtags in text, and how each was first written
+func (g Grammar) find(text string) (keys []string, names []string) {
+	seen := make(map[string]bool)
+	for _, t := range g.Tokenize(text) {
+		if !seen[t.Key] {
+			seen[t.Key] = true
+			keys = append(keys, t.Key)
+			names = append(names, t.Name)
+		}
+	}
+	return keys, names
+}
+
+// Tag is a hashtag and how it has been used
+type Tag struct {
+	Key         string    // for comparing, eg #café
+	Name        string    // as it is most often written, eg #Café
+	Count       int       // number of notes using it
+	First, Last time.Time // dates of the first and last notes using it, zero if it is only used in undated notes
+	names       map[string]int
+}
+
+// noteTags are the tags used in a note
+type noteTags struct {
+	date  time.Time
+	keys  []string
+	names []string // how each tag was written in the note
+}
+
+// Catalogue knows which tags are used in which notes of a journal
+type Catalogue struct {
+	grammar Grammar
+	notes   map[string]noteTags // keyed by pathname
+}
+
+// NewCatalogue returns an empty catalogue of tags written in grammar g
+func NewCatalogue(g Grammar) *Catalogue {
+	return &Catalogue{grammar: g, notes: make(map[string]noteTags)}
+}
+
+// Index notes the tags used in a note
+func (c *Catalogue) Index(n *note.Note) {
+	if keys, names := c.grammar.find(n.Text); len(keys) > 0 {
+		c.notes[n.Pathname] = noteTags{date: n.Date, keys: keys, names: names}
+	} else {
+		delete(c.notes, n.Pathname)
+	}
+}
+
+// Keys returns the keys of the tags used in a note, in the order they first appear
+func (c *Catalogue) Keys(pathname string) []string {
+	return c.notes[pathname].keys
+}
+
+// Tags returns every tag used, sorted by key
+func (c *Catalogue) Tags() []Tag {
+	byKey := make(map[string]*Tag)
+	for _, nt := range c.notes {
+		for i, key := range nt.keys {
+			tagFor(byKey, key).use(nt.date, nt.names[i])
+		}
+	}
+	return sorted(byKey, func(a, b Tag) bool { return a.Key < b.Key })
+}
+
+// CoOccurring returns the other tags used in the same notes as the one with the given key,
+// with Count being the number of notes they share, most shared first
+func (c *Catalogue) CoOccurring(key string) []Tag {
+	byKey := make(map[string]*Tag)
+	for _, nt := range c.notes {
+		if !util.Contains(nt.keys, key) {
+			continue
+		}
+		for i, other := range nt.keys {
+			if other != key {
+				tagFor(byKey, other).use(nt.date, nt.names[i])
+			}
+		}
+	}
+	return sorted(byKey, func(a, b Tag) bool {
+		if a.Count != b.Count {
+			return a.Count > b.Count
+		}
+		return a.Key < b.Key
+	})
+}
+
+// Complete returns the tags starting with prefix, those used most, and most recently, first
+func (c *Catalogue) Complete(prefix string, now time.Time) []Tag {
+	var matching []Tag
+	for _, t := range c.Tags() {
+		if strings.HasPrefix(t.Key, Key(prefix)) {
+			matching = append(matching, t)
+		}
+	}
+	// a tag's score fades the longer it has gone unused
+	score := func(t Tag) float64 {
+		return float64(t.Count) / (1 + now.Sub(t.Last).Hours()/24/30)
+	}
+	sort.SliceStable(matching, func(i, j int) bool {
+		return score(matching[i]) > score(matching[j])
+	})
+	return matching
+}
+
+// Span returns the dates of the first and last dated notes using any tag
+func (c *Catalogue) Span() (first, last time.Time) {
+	for _, nt := range c.notes {
+		if nt.date.Year() == 1 {
+			continue
+		}
+		if first.IsZero() || nt.date.Before(first) {
+			first = nt.date
+		}
+		if nt.date.After(last) {
+			last = nt.date
+		}
+	}
+	return first, last
+}
+
+// Usage counts the dated notes using each tag, by key, in each of a number of equal periods from first to last
+func (c *Catalogue) Usage(first, last time.Time, periods int) map[string][]int {
+	usage := make(map[string][]int)
+	span := last.Sub(first)
+	for _, nt := range c.notes {
+		if nt.date.Year() == 1 || nt.date.Before(first) || nt.date.After(last) {
+			continue
+		}
+		i := periods - 1
+		if span > 0 {
+			i = int(int64(periods) * int64(nt.date.Sub(first)) / int64(span+1))
+		}
+		for _, key := range nt.keys {
+			if usage[key] == nil {
+				usage[key] = make([]int, periods)
+			}
+			usage[key][i]++
+		}
+	}
+	return usage
+}
+
+var sparks = []rune("▁▂▃▄▅▆▇█")
+
+// Sparkline draws counts as a row of bars of different heights, with a space for nothing
+func Sparkline(counts []int) string {
+	max := 0
+	for _, n := range counts {
+		if n > max {
+			max = n
+		}
+	}
+	var b strings.Builder
+	for _, n := range counts {
+		if n == 0 {
+			b.WriteRune(' ')
+		} else {
+			b.WriteRune(sparks[(n*len(sparks)-1)/max])
+		}
+	}
+	return b.String()
+}
+
+func tagFor(byKey map[string]*Tag, key string) *Tag {
+	t, ok := byKey[key]
+	if !ok {
+		t = &Tag{Key: key, names: make(map[string]int)}
+		byKey[key] = t
+	}
+	return t
+}
+
+// use counts a note using the tag, written as name
+func (t *Tag) use(date time.Time, name string) {
+	t.Count++
+	t.names[name]++
+	if n := t.names[name]; n > t.names[t.Name] || (n == t.names[t.Name] && name < t.Name) {
+		t.Name = name
+	}
+	if date.Year() == 1 {
+		return
+	}
+	if t.First.IsZero() || date.Before(t.First) {
+		t.First = date
+	}
+	if date.After(t.Last) {
+		t.Last = date
+	}
+}
+
+func sorted(byKey map[string]*Tag, less func(a, b Tag) bool) []Tag {
+	tags := make([]Tag, 0, len(byKey))
+	for _, t := range byKey {
+		t.names = nil
+		tags = append(tags, *t)
+	}
+	sort.Slice(tags, func(i, j int) bool { return less(tags[i], tags[j]) })
+	return tags
+}
@@ -0,0 +1,207 @@
+package main
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/widget"
+	"oddstream.cj/note"
+	"oddstream.cj/tasks"
+	"oddstream.cj/util"
+)
+
+// which tasks the task panel shows
+const (
+	tasksOpen = "Open"
+	tasksDue  = "Due"
+	tasksDone = "Done"
+	tasksAll  = "All"
+)
+
+// how the task panel groups the tasks
+const (
+	tasksByDate = "By date"
+	tasksByTag  = "By tag"
+)
+
+// taskRow is a row of the task panel, either a heading or a task
+type taskRow struct {
+	heading string
+	task    tasks.Task
+}
+
+var (
+	theTaskCatalogue *tasks.Catalogue // the tasks in the current journal
+	theTaskRows      []taskRow        // as shown in the task panel
+)
+
+// buildTaskPanel makes the Tasks tab, listing the tasks in every note of the journal
+func (u *ui) buildTaskPanel() fyne.CanvasObject {
+	u.taskList = widget.NewList(
+		func() int {
+			return len(theTaskRows)
+		},
+		func() fyne.CanvasObject {
+			heading := widget.NewLabel("")
+			heading.TextStyle = fyne.TextStyle{Bold: true}
+			check := widget.NewCheck("", nil)
+			text := widget.NewLabel("")
+			text.Wrapping = fyne.TextTruncate
+			task := container.New(layout.NewBorderLayout(nil, nil, check, nil), check, text)
+			return container.New(layout.NewMaxLayout(), heading, task)
+		},
+		func(id widget.ListItemID, obj fyne.CanvasObject) {
+			row := theTaskRows[id]
+			c := obj.(*fyne.Container)
+			heading, task := c.Objects[0].(*widget.Label), c.Objects[1].(*fyne.Container)
+			if row.heading != "" {
+				heading.SetText(row.heading)
+				heading.Show()
+				task.Hide()
+				return
+			}
+			heading.Hide()
+			task.Show()
+			check := task.Objects[0].(*widget.Check)
+			check.OnChanged = nil // don't call back while setting up the row
+			check.SetChecked(row.task.Done)
+			check.OnChanged = func(bool) {
+				u.toggleTask(row.task)
+			}
+			task.Objects[1].(*widget.Label).SetText(row.task.Text)
+		},
+	)
+	u.taskList.OnSelected = func(id widget.ListItemID) {
+		u.taskList.UnselectAll()
+		if row := theTaskRows[id]; row.heading == "" {
+			u.openTask(row.task)
+		}
+	}
+	u.taskFilter = widget.NewRadioGroup([]string{tasksOpen, tasksDue, tasksDone, tasksAll}, func(string) {
+		u.refreshTasks()
+	})
+	u.taskFilter.Horizontal = true
+	u.taskFilter.Required = true
+	u.taskFilter.Selected = tasksOpen
+	u.taskGroup = widget.NewSelect([]string{tasksByDate, tasksByTag}, func(string) {
+		u.refreshTasks()
+	})
+	u.taskGroup.Selected = tasksByDate
+	habits := widget.NewButton("Habits", func() {
+		u.showHabits()
+	})
+	group := container.New(layout.NewBorderLayout(nil, nil, nil, habits), habits, u.taskGroup)
+	top := container.New(layout.NewVBoxLayout(), u.taskFilter, group)
+	return container.New(layout.NewBorderLayout(top, nil, nil, nil), top, u.taskList)
+}
+
+// refreshTasks lists the tasks in the catalogue that pass the filter, under headings
+func (u *ui) refreshTasks() {
+	if u.taskFilter.Selected == tasksDue {
+		theTaskRows = dueRows(today())
+		u.taskList.Refresh()
+		return
+	}
+	var shown []tasks.Task
+	for _, t := range theTaskCatalogue.Tasks() {
+		if u.taskFilter.Selected == tasksAll || t.Done == (u.taskFilter.Selected == tasksDone) {
+			shown = append(shown, t)
+		}
+	}
+	if u.taskGroup.Selected == tasksByTag {
+		theTaskRows = tasksByTagRows(shown)
+	} else {
+		theTaskRows = tasksByDateRows(shown)
+	}
+	u.taskList.Refresh()
+}
+
+func tasksByDateRows(shown []tasks.Task) []taskRow {
+	var rows []taskRow
+	var pathname string
+	for _, t := range shown {
+		if t.Pathname != pathname {
+			pathname = t.Pathname
+			rows = append(rows, taskRow{heading: noteTitle(note.NewNote(theJournalDir, theDirectory, t.Pathname))})
+		}
+		rows = append(rows, taskRow{task: t})
+	}
+	return rows
+}
+
+// tasksByTagRows lists the tasks under each of their tags, in order of tag, and then those without a tag
+func tasksByTagRows(shown []tasks.Task) []taskRow {
+	var rows []taskRow
+	for _, tag := range theCatalogue.Tags() {
+		var tagged []taskRow
+		for _, t := range shown {
+			if util.Contains(t.Tags, tag.Key) {
+				tagged = append(tagged, taskRow{task: t})
+			}
+		}
+		if len(tagged) > 0 {
+			rows = append(rows, taskRow{heading: tag.Name})
+			rows = append(rows, tagged...)
+		}
+	}
+	var untagged []taskRow
+	for _, t := range shown {
+		if len(t.Tags) == 0 {
+			untagged = append(untagged, taskRow{task: t})
+		}
+	}
+	if len(untagged) > 0 {
+		rows = append(rows, taskRow{heading: "No tag"})
+		rows = append(rows, untagged...)
+	}
+	return rows
+}
+
+// toggleTask ticks a task, or clears it, in its note
+func (u *ui) toggleTask(t tasks.Task) {
+	u.saveNote() // so the line is where the catalogue thinks it is, if it's in the note being edited
+	n := note.NewNote(theJournalDir, theDirectory, t.Pathname)
+	n.Load()
+	text, err := tasks.Toggle(n.Text, t.Line, t.Text)
+	if err != nil {
+		dialog.ShowError(err, u.mainWindow)
+	} else if changed, err := n.SaveIfDirty(text); err != nil {
+		dialog.ShowError(err, u.mainWindow)
+	} else if changed && t.Pathname == theNote.Pathname {
+		u.reloadNote()
+	}
+	u.notesChanged([]string{t.Pathname})
+}
+
+// openTask opens the note of a task, selecting its line
+func (u *ui) openTask(t tasks.Task) {
+	u.saveNote()
+	if t.Pathname != theNote.Pathname {
+		u.setCurrentNote(note.NewNote(theJournalDir, theDirectory, t.Pathname))
+	}
+	u.selectLine(t.Line)
+	u.mainWindow.Canvas().Focus(u.noteEntry)
+}
+
+// selectLine selects a line, counting from 1, of the note being edited
+func (u *ui) selectLine(line int) {
+	lines := strings.Split(u.noteEntry.Text, "\n")
+	if line < 1 || line > len(lines) {
+		return
+	}
+	var start int
+	for _, s := range lines[:line-1] {
+		start += utf8.RuneCountInString(s) + 1
+	}
+	u.noteEntry.Select(start, start+utf8.RuneCountInString(lines[line-1]))
+}
+
+// tasksChanged shows the tasks as they are after the notes have been changed
+func (u *ui) tasksChanged() {
+	u.refreshTasks()
+	u.showDue()
+}
@@ -0,0 +1,156 @@
+package tasks
+
+import (
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// recurPattern is how often a task recurs, written as
+//
+//	@every(day)       every day
+//	@every(weekday)   every Monday to Friday
+//	@every(week)      every seven days
+//	@every(3d)        every three days
+//	@every(month:15)  on the 15th of every month, or the last day of shorter months
+//
+// counting from the day of the first note the task is in
+var recurPattern = regexp.MustCompile(`@every\(([^)]*)\)`)
+
+// Rule is how often a task recurs
+type Rule struct {
+	Days     int  // every so many days, if not zero
+	Weekdays bool // every Monday to Friday
+	MonthDay int  // on this day of every month, if not zero
+}
+
+// parseRule returns how often the task in text recurs, or nil if it doesn't
+func parseRule(text string) *Rule {
+	m := recurPattern.FindStringSubmatch(text)
+	if m == nil {
+		return nil
+	}
+	value := strings.ToLower(strings.TrimSpace(m[1]))
+	switch {
+	case value == "day":
+		return &Rule{Days: 1}
+	case value == "week":
+		return &Rule{Days: 7}
+	case value == "weekday":
+		return &Rule{Weekdays: true}
+	case strings.HasPrefix(value, "month:"):
+		if d, err := strconv.Atoi(value[6:]); err == nil && d >= 1 && d <= 31 {
+			return &Rule{MonthDay: d}
+		}
+	case strings.HasSuffix(value, "d"):
+		if n, err := strconv.Atoi(value[:len(value)-1]); err == nil && n >= 1 {
+			return &Rule{Days: n}
+		}
+	}
+	return nil
+}
+
+// On reports whether a task started on origin recurs on day
+func (r Rule) On(day, origin time.Time) bool {
+	if day.Before(origin) {
+		return false
+	}
+	switch {
+	case r.Weekdays:
+		return day.Weekday() != time.Saturday && day.Weekday() != time.Sunday
+	case r.MonthDay > 0:
+		last := time.Date(day.Year(), day.Month()+1, 0, 0, 0, 0, 0, day.Location()).Day()
+		if r.MonthDay > last {
+			return day.Day() == last
+		}
+		return day.Day() == r.MonthDay
+	case r.Days > 0:
+		return days(origin, day)%r.Days == 0
+	}
+	return false
+}
+
+// days returns the number of whole days from one day to another, ignoring daylight saving
+func days(from, to time.Time) int {
+	a := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, time.UTC)
+	b := time.Date(to.Year(), to.Month(), to.Day(), 0, 0, 0, 0, time.UTC)
+	return int(b.Sub(a).Hours() / 24)
+}
+
+// Series is a recurring task and what happened to each of its occurrences
+type Series struct {
+	Text   string // the text of the task, which is the same in every occurrence
+	Rule   Rule
+	Origin time.Time          // the day of the first occurrence
+	Done   map[time.Time]bool // whether each day's occurrence was done, for the days that have one
+	Tags   []string
+}
+
+// Occurrences returns the days the task recurs on, from its origin up to and including a day
+func (s Series) Occurrences(to time.Time) []time.Time {
+	var occs []time.Time
+	for d := s.Origin; !d.After(to); d = d.AddDate(0, 0, 1) {
+		if s.Rule.On(d, s.Origin) {
+			occs = append(occs, d)
+		}
+	}
+	return occs
+}
+
+// Streaks returns how many occurrences in a row have been done, up to a day, and the most there have ever been;
+// an occurrence on the day itself only counts once it has been done, as there is still time to do it
+func (s Series) Streaks(day time.Time) (current, best int) {
+	occs := s.Occurrences(day)
+	run := 0
+	for i, d := range occs {
+		if s.Done[d] {
+			run++
+		} else if !(i == len(occs)-1 && d.Equal(day)) {
+			run = 0
+		}
+		if run > best {
+			best = run
+		}
+	}
+	return run, best
+}
+
+// Series returns every recurring task, in order of text
+func (c *Catalogue) Series() []Series {
+	byText := make(map[string]*Series)
+	for _, ts := range c.notes {
+		for _, t := range ts {
+			if t.Recurs == nil || t.Date.Year() == 1 {
+				continue
+			}
+			s, ok := byText[t.Text]
+			if !ok {
+				s = &Series{Text: t.Text, Rule: *t.Recurs, Origin: t.Date, Done: make(map[time.Time]bool), Tags: t.Tags}
+				byText[t.Text] = s
+			}
+			if t.Date.Before(s.Origin) {
+				s.Origin = t.Date
+			}
+			s.Done[t.Date] = s.Done[t.Date] || t.Done
+		}
+	}
+	series := make([]Series, 0, len(byText))
+	for _, s := range byText {
+		series = append(series, *s)
+	}
+	sort.Slice(series, func(i, j int) bool { return series[i].Text < series[j].Text })
+	return series
+}
+
+// Recurring returns the recurring tasks that recur on a day, but aren't in its note yet
+func (c *Catalogue) Recurring(day time.Time) []Series {
+	var due []Series
+	for _, s := range c.Series() {
+		if _, ok := s.Done[day]; !ok && day.After(s.Origin) && s.Rule.On(day, s.Origin) {
+			due = append(due, s)
+		}
+	}
+	return due
+}
@@ -0,0 +1,127 @@
+package tasks
+
+import (
+	"testing"
+	"time"
+
+	"oddstream.cj/note"
+	"oddstream.cj/tags"
+)
+
+func day(y int, m time.Month, d int) time.Time {
+	return time.Date(y, m, d, 0, 0, 0, 0, time.Local)
+}
+
+func TestParseRule(t *testing.T) {
+	tests := []struct {
+		text string
+		want *Rule
+	}{
+		{"[ ] stretch @every(day)", &Rule{Days: 1}},
+		{"@every(Week)", &Rule{Days: 7}},
+		{"@every( weekday )", &Rule{Weekdays: true}},
+		{"@every(3d)", &Rule{Days: 3}},
+		{"@every(month:31)", &Rule{MonthDay: 31}},
+		{"no rule", nil},
+		{"@every()", nil},
+		{"@every(0d)", nil},
+		{"@every(month:32)", nil},
+		{"@every(month:0)", nil},
+		{"@every(fortnight)", nil},
+	}
+	for _, tt := range tests {
+		got := parseRule(tt.text)
+		if (got == nil) != (tt.want == nil) || (got != nil && *got != *tt.want) {
+			t.Errorf("parseRule(%q) = %+v, want %+v", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestOn(t *testing.T) {
+	origin := day(2023, time.January, 1) // a Sunday
+	tests := []struct {
+		rule Rule
+		day  time.Time
+		want bool
+	}{
+		{Rule{Days: 1}, origin, true},
+		{Rule{Days: 1}, day(2022, time.December, 31), false}, // before it started
+		{Rule{Days: 3}, day(2023, time.January, 4), true},
+		{Rule{Days: 3}, day(2023, time.January, 5), false},
+		{Rule{Days: 7}, day(2023, time.April, 2), true},    // across the start of summer time
+		{Rule{Days: 7}, day(2023, time.November, 5), true}, // and the end of it
+		{Rule{Weekdays: true}, day(2023, time.January, 6), true},
+		{Rule{Weekdays: true}, day(2023, time.January, 7), false},
+		{Rule{Weekdays: true}, day(2023, time.January, 8), false},
+		{Rule{Weekdays: true}, day(2023, time.January, 9), true},
+		{Rule{MonthDay: 15}, day(2023, time.February, 15), true},
+		{Rule{MonthDay: 15}, day(2023, time.February, 16), false},
+		// on the last day of months too short to have the day
+		{Rule{MonthDay: 31}, day(2023, time.April, 30), true},
+		{Rule{MonthDay: 31}, day(2023, time.April, 29), false},
+		{Rule{MonthDay: 31}, day(2023, time.May, 31), true},
+		{Rule{MonthDay: 30}, day(2023, time.February, 28), true},
+		{Rule{MonthDay: 29}, day(2024, time.February, 29), true}, // a leap year
+		{Rule{MonthDay: 29}, day(2024, time.February, 28), false},
+		{Rule{MonthDay: 31}, day(2024, time.February, 29), true},
+		{Rule{}, origin, false},
+	}
+	for _, tt := range tests {
+		if got := tt.rule.On(tt.day, origin); got != tt.want {
+			t.Errorf("%+v.On(%s) = %v, want %v", tt.rule, tt.day.Format("2006-01-02"), got, tt.want)
+		}
+	}
+}
+
+func TestStreaks(t *testing.T) {
+	s := Series{Rule: Rule{Days: 1}, Origin: day(2023, time.July, 1), Done: map[time.Time]bool{
+		day(2023, time.July, 1): true,
+		day(2023, time.July, 2): true,
+		day(2023, time.July, 3): true,
+		day(2023, time.July, 4): false,
+		day(2023, time.July, 5): true,
+	}}
+	tests := []struct {
+		day           time.Time
+		current, best int
+	}{
+		{day(2023, time.July, 3), 3, 3},
+		{day(2023, time.July, 4), 3, 3}, // today's hasn't been done yet, but there's still time
+		{day(2023, time.July, 5), 1, 3},
+		{day(2023, time.July, 6), 1, 3},
+		{day(2023, time.July, 7), 0, 3}, // the 6th was missed
+	}
+	for _, tt := range tests {
+		if current, best := s.Streaks(tt.day); current != tt.current || best != tt.best {
+			t.Errorf("Streaks(%s) = %d, %d, want %d, %d", tt.day.Format("2006-01-02"), current, best, tt.current, tt.best)
+		}
+	}
+}
+
+func TestRecurring(t *testing.T) {
+	c := NewCatalogue(tags.Grammar{})
+	note.NewIndex("Default", writeJournal(t, map[string]string{
+		"01": "[ ] water the plants @every(2d)\n[ ] pay rent @every(month:3)\n[ ] one off\n",
+		"03": "[x] water the plants @every(2d)\n",
+	}), c)
+	tests := []struct {
+		day  time.Time
+		want []string
+	}{
+		{day(2023, time.July, 1), nil}, // the tasks started here
+		{day(2023, time.July, 2), nil},
+		{day(2023, time.July, 3), []string{"pay rent @every(month:3)"}}, // the plants are already in the note
+		{day(2023, time.July, 5), []string{"water the plants @every(2d)"}},
+		{day(2023, time.August, 3), []string{"pay rent @every(month:3)"}},
+		{day(2023, time.June, 29), nil}, // before either started
+	}
+	for _, tt := range tests {
+		var got []string
+		for _, s := range c.Recurring(tt.day) {
+			got = append(got, s.Text)
+		}
+		if !equalStrings(got, tt.want) {
+			t.Errorf("Recurring(%s) = %q, want %q", tt.day.Format("2006-01-02"), got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package tasks
+
+import (
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"oddstream.cj/util"
+)
+
+// remindPattern is a time of day to be reminded of a line, written as @remind(14:30),
+// on the day of the note the line is in
+var remindPattern = regexp.MustCompile(`@remind\((\d{1,2}:\d{2})\)`)
+
+// Reminder is a line of a note to be reminded of at a certain time,
+// either because it says @remind(14:30) or because it is a task due at a time, like @due(2023-08-01 14:30)
+type Reminder struct {
+	Pathname string // of the note
+	Line     int    // line number, starting at 1
+	Text     string // the line
+	At       time.Time
+}
+
+// Key identifies a reminder, whichever line it has moved to
+func (r Reminder) Key() string {
+	return r.At.Format("2006-01-02T15:04") + " " + r.Text
+}
+
+// ParseReminders returns the reminders in the text of a note dated date, in order;
+// lines of an undated note can only remind with a due date, and tasks that are done don't remind at all
+func ParseReminders(text string, date time.Time) []Reminder {
+	var reminders []Reminder
+	for i, line := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n") {
+		if m := taskPattern.FindStringSubmatch(line); m != nil && m[2] != " " {
+			continue
+		}
+		var at time.Time
+		if m := remindPattern.FindStringSubmatch(line); m != nil && date.Year() != 1 {
+			at, _ = atClock(util.DayOf(date), m[1])
+		} else if due := parseDue(line); !due.IsZero() && !due.Equal(util.DayOf(due)) {
+			at = due
+		}
+		if !at.IsZero() {
+			reminders = append(reminders, Reminder{Line: i + 1, Text: strings.TrimSpace(line), At: at})
+		}
+	}
+	return reminders
+}
+
+// atClock returns the time on day given by clock, like 14:30, reporting whether clock makes sense
+func atClock(day time.Time, clock string) (time.Time, bool) {
+	h, m, _ := strings.Cut(clock, ":")
+	hour, err1 := strconv.Atoi(h)
+	minute, err2 := strconv.Atoi(m)
+	if err1 != nil || err2 != nil || hour > 23 || minute > 59 {
+		return time.Time{}, false
+	}
+	return time.Date(day.Year(), day.Month(), day.Day(), hour, minute, 0, 0, day.Location()), true
+}
+
+// Reminders returns the reminders from a time on, soonest first
+func (c *Catalogue) Reminders(from time.Time) []Reminder {
+	var reminders []Reminder
+	for _, rs := range c.reminders {
+		for _, r := range rs {
+			if !r.At.Before(from) {
+				reminders = append(reminders, r)
+			}
+		}
+	}
+	sort.Slice(reminders, func(i, j int) bool { return reminders[i].At.Before(reminders[j].At) })
+	return reminders
+}
@@ -0,0 +1,53 @@
+package tasks
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseReminders(t *testing.T) {
+	date := day(2023, time.July, 4)
+	at := func(d, h, m int) time.Time { return time.Date(2023, time.July, d, h, m, 0, 0, time.Local) }
+	text := "dentist @remind(14:30)\n" +
+		"[ ] call the bank @due(2023-07-05 9:15)\n" +
+		"[x] done already @remind(10:00)\n" +
+		"[ ] due all day @due(2023-07-06)\n" +
+		"nonsense @remind(25:00)\n" +
+		"  - [ ] ring back @remind(8:05)\r\n" +
+		"plain line\n"
+	want := []Reminder{
+		{Line: 1, Text: "dentist @remind(14:30)", At: at(4, 14, 30)},
+		{Line: 2, Text: "[ ] call the bank @due(2023-07-05 9:15)", At: at(5, 9, 15)},
+		{Line: 6, Text: "- [ ] ring back @remind(8:05)", At: at(4, 8, 5)},
+	}
+	got := ParseReminders(text, date)
+	if len(got) != len(want) {
+		t.Fatalf("ParseReminders found %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i].Line != want[i].Line || got[i].Text != want[i].Text || !got[i].At.Equal(want[i].At) {
+			t.Errorf("reminder %d is %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestParseRemindersUndated(t *testing.T) {
+	// an undated note has no day for @remind, but a due date brings its own
+	got := ParseReminders("a @remind(14:30)\n[ ] b @due(2023-07-05 9:15)\n", time.Time{})
+	if len(got) != 1 || got[0].Line != 2 {
+		t.Errorf("ParseReminders of an undated note = %+v, want only line 2", got)
+	}
+}
+
+func TestReminderKey(t *testing.T) {
+	a := Reminder{Line: 1, Text: "dentist", At: time.Date(2023, time.July, 4, 14, 30, 0, 0, time.Local)}
+	b := a
+	b.Line = 7 // moved, but the same reminder
+	if a.Key() != b.Key() {
+		t.Errorf("keys %q and %q differ", a.Key(), b.Key())
+	}
+	b.At = b.At.Add(time.Minute)
+	if a.Key() == b.Key() {
+		t.Errorf("reminders at different times have the same key %q", a.Key())
+	}
+}
@@ -0,0 +1,181 @@
+package tasks
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"oddstream.cj/note"
+	"oddstream.cj/tags"
+	"oddstream.cj/util"
+)
+
+var taskPattern = regexp.MustCompile(`^(\s*(?:[-*+]\s+)?)\[([ xX])\](.*)$`)
+
+// duePattern is when a task is due, written as @due(2023-08-01) or >2023-08-01,
+// optionally with a time, as in @due(2023-08-01 14:30) or >2023-08-01T14:30
+var duePattern = regexp.MustCompile(`@due\((\d{4}-\d{2}-\d{2})(?:[ T](\d{1,2}:\d{2}))?\)|(?:^|\s)>(\d{4}-\d{2}-\d{2})(?:T(\d{1,2}:\d{2}))?\b`)
+
+// Task is a line of a note with a box to tick, like
+//
+//	[ ] buy milk
+//	- [X] post the letter #errands
+//
+// A task is open while its box is empty, and done once it holds an X.
+// Its tags are the ones on its line, and any scoped to its paragraph (see tags.Blocks).
+// It is due on a day if the line says so, as in [ ] write report @due(2023-08-01) or >2023-08-01,
+// and it recurs if the line says how often, as in [ ] send invoice @every(month:1) (see recurPattern).
+type Task struct {
+	Pathname string    // of the note
+	Date     time.Time // of the note
+	Line     int       // line number, starting at 1
+	Text     string    // the line after the box
+	Done     bool
+	Tags     []string  // keys
+	Due      time.Time // zero if the task isn't due on any particular day
+	Recurs   *Rule     // nil if the task doesn't recur
+}
+
+// Parse returns the tasks in the text of a note, with tags written in grammar g, in order
+func Parse(text string, g tags.Grammar) []Task {
+	var tasks []Task
+	lines := strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+	blocks := g.Blocks(text)
+	for i, line := range lines {
+		m := taskPattern.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		var scoped []string
+		for _, b := range blocks {
+			if b.Contains(i + 1) {
+				scoped = b.Tags
+				break
+			}
+		}
+		t := Task{Line: i + 1, Text: strings.TrimSpace(m[3]), Done: m[2] != " ", Due: util.DayOf(parseDue(m[3])), Recurs: parseRule(m[3])}
+		for _, key := range append(append([]string{}, scoped...), g.Find(line)...) {
+			if !util.Contains(t.Tags, key) {
+				t.Tags = append(t.Tags, key)
+			}
+		}
+		tasks = append(tasks, t)
+	}
+	return tasks
+}
+
+// parseDue returns when a task is due, or zero if it isn't; if no time is given, it is due at the start of the day
+func parseDue(text string) time.Time {
+	m := duePattern.FindStringSubmatch(text)
+	if m == nil {
+		return time.Time{}
+	}
+	date, clock := m[1]+m[3], m[2]+m[4] // only one of the forms matched
+	due, err := time.ParseInLocation("2006-01-02", date, time.Local)
+	if err != nil {
+		return time.Time{} // like 2023-02-30
+	}
+	if at, ok := atClock(due, clock); ok {
+		return at
+	}
+	return due
+}
+
+// Toggle ticks the box of the task on line (counting from 1) of text, or clears it if it is ticked.
+// It fails if the line isn't a task with the text task, which means the note has been changed since.
+func Toggle(text string, line int, task string) (string, error) {
+	lines := strings.Split(text, "\n")
+	if line < 1 || line > len(lines) {
+		return "", fmt.Errorf("line %d has gone", line)
+	}
+	m := taskPattern.FindStringSubmatchIndex(lines[line-1])
+	if m == nil || strings.TrimSpace(lines[line-1][m[6]:m[7]]) != task {
+		return "", fmt.Errorf("line %d is no longer %q", line, task)
+	}
+	mark := "X"
+	if lines[line-1][m[4]:m[5]] != " " {
+		mark = " "
+	}
+	lines[line-1] = lines[line-1][:m[4]] + mark + lines[line-1][m[5]:]
+	return strings.Join(lines, "\n"), nil
+}
+
+// Catalogue knows the tasks and reminders in every note of a journal
+type Catalogue struct {
+	grammar   tags.Grammar
+	notes     map[string][]Task     // keyed by pathname
+	reminders map[string][]Reminder // keyed by pathname
+}
+
+// NewCatalogue returns an empty catalogue of tasks, with tags written in grammar g
+func NewCatalogue(g tags.Grammar) *Catalogue {
+	return &Catalogue{grammar: g, notes: make(map[string][]Task), reminders: make(map[string][]Reminder)}
+}
+
+// Index notes the tasks and reminders in a note
+func (c *Catalogue) Index(n *note.Note) {
+	if reminders := ParseReminders(n.Text, n.Date); len(reminders) > 0 {
+		for i := range reminders {
+			reminders[i].Pathname = n.Pathname
+		}
+		c.reminders[n.Pathname] = reminders
+	} else {
+		delete(c.reminders, n.Pathname)
+	}
+	tasks := Parse(n.Text, c.grammar)
+	if len(tasks) == 0 {
+		delete(c.notes, n.Pathname)
+		return
+	}
+	for i := range tasks {
+		tasks[i].Pathname = n.Pathname
+		tasks[i].Date = n.Date
+	}
+	c.notes[n.Pathname] = tasks
+}
+
+// Tasks returns every task, oldest first
+func (c *Catalogue) Tasks() []Task {
+	var tasks []Task
+	for _, ts := range c.notes {
+		tasks = append(tasks, ts...)
+	}
+	sort.Slice(tasks, func(i, j int) bool {
+		if !tasks[i].Date.Equal(tasks[j].Date) {
+			return tasks[i].Date.Before(tasks[j].Date)
+		}
+		if tasks[i].Pathname != tasks[j].Pathname {
+			return tasks[i].Pathname < tasks[j].Pathname
+		}
+		return tasks[i].Line < tasks[j].Line
+	})
+	return tasks
+}
+
+// Due returns the open tasks due from one day to another, inclusive, soonest first;
+// a zero time leaves that end of the range open
+func (c *Catalogue) Due(from, to time.Time) []Task {
+	var due []Task
+	for _, ts := range c.notes {
+		for _, t := range ts {
+			if t.Done || t.Due.IsZero() {
+				continue
+			}
+			if (from.IsZero() || !t.Due.Before(from)) && (to.IsZero() || !t.Due.After(to)) {
+				due = append(due, t)
+			}
+		}
+	}
+	sort.Slice(due, func(i, j int) bool {
+		if !due[i].Due.Equal(due[j].Due) {
+			return due[i].Due.Before(due[j].Due)
+		}
+		if due[i].Pathname != due[j].Pathname {
+			return due[i].Pathname < due[j].Pathname
+		}
+		return due[i].Line < due[j].Line
+	})
+	return due
+}
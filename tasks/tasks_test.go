@@ -0,0 +1,163 @@
+package tasks
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"oddstream.cj/note"
+	"oddstream.cj/tags"
+)
+
+// writeJournal makes a journal in a temporary directory with a note for each day of July 2023 in notes
+func writeJournal(t *testing.T, notes map[string]string) string {
+	t.Helper()
+	directory := t.TempDir()
+	for day, text := range notes {
+		pathname := filepath.Join(directory, "2023", "07", day+".txt")
+		if err := os.MkdirAll(filepath.Dir(pathname), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(pathname, []byte(text), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return directory
+}
+
+func TestParse(t *testing.T) {
+	text := "#work\n" +
+		"[ ] write report\n" +
+		"- [x] post the letter #errands\n" +
+		"  * [X] indented and starred\n" +
+		"\n" +
+		"[] not a box\n" +
+		"a [ ] box mid line\n" +
+		"+ [ ]\n"
+	want := []Task{
+		{Line: 2, Text: "write report", Tags: []string{"#work"}},
+		{Line: 3, Text: "post the letter #errands", Done: true, Tags: []string{"#work", "#errands"}},
+		{Line: 4, Text: "indented and starred", Done: true, Tags: []string{"#work"}},
+		{Line: 8, Text: ""},
+	}
+	got := Parse(text, tags.Grammar{})
+	if len(got) != len(want) {
+		t.Fatalf("Parse found %d tasks, want %d: %+v", len(got), len(want), got)
+	}
+	for i := range want {
+		g, w := got[i], want[i]
+		if g.Line != w.Line || g.Text != w.Text || g.Done != w.Done || !equalStrings(g.Tags, w.Tags) {
+			t.Errorf("task %d is %+v, want %+v", i, g, w)
+		}
+	}
+}
+
+func TestParseCRLF(t *testing.T) {
+	got := Parse("[ ] one\r\n[x] two\r\n", tags.Grammar{})
+	if len(got) != 2 || got[0].Text != "one" || got[1].Text != "two" || !got[1].Done {
+		t.Errorf("Parse of CRLF lines = %+v", got)
+	}
+}
+
+func TestParseDue(t *testing.T) {
+	at := func(d, h, m int) time.Time { return time.Date(2023, time.August, d, h, m, 0, 0, time.Local) }
+	tests := []struct {
+		text string
+		want time.Time // zero if it isn't due
+	}{
+		{"write report @due(2023-08-01)", at(1, 0, 0)},
+		{"write report >2023-08-02", at(2, 0, 0)},
+		{">2023-08-03 at the start", at(3, 0, 0)},
+		{"call @due(2023-08-04 14:30)", at(4, 14, 30)},
+		{"call >2023-08-05T9:05", at(5, 9, 5)},
+		{"call @due(2023-08-06 25:00)", at(6, 0, 0)}, // a time that makes no sense is ignored
+		{"no date", time.Time{}},
+		{"a>2023-08-07 needs a space before it", time.Time{}},
+		{"@due(2023-02-30)", time.Time{}},
+		{"@due(23-08-01)", time.Time{}},
+	}
+	for _, tt := range tests {
+		if got := parseDue(tt.text); !got.Equal(tt.want) {
+			t.Errorf("parseDue(%q) = %v, want %v", tt.text, got, tt.want)
+		}
+	}
+	// a task is due on the day, whatever the time
+	if got := Parse("[ ] call @due(2023-08-04 14:30)", tags.Grammar{}); len(got) != 1 || !got[0].Due.Equal(at(4, 0, 0)) {
+		t.Errorf("Parse gave %+v, want a task due on %v", got, at(4, 0, 0))
+	}
+}
+
+func TestDue(t *testing.T) {
+	c := NewCatalogue(tags.Grammar{})
+	note.NewIndex("Default", writeJournal(t, map[string]string{
+		"01": "[ ] late >2023-07-20\n[x] done >2023-07-21\n",
+		"02": "[ ] soon >2023-07-25\n[ ] sooner >2023-07-22 14:00\n[ ] whenever\n",
+	}), c)
+	day := func(d int) time.Time { return time.Date(2023, time.July, d, 0, 0, 0, 0, time.Local) }
+	tests := []struct {
+		from, to time.Time
+		want     []string
+	}{
+		{time.Time{}, time.Time{}, []string{"late >2023-07-20", "sooner >2023-07-22 14:00", "soon >2023-07-25"}},
+		{day(21), day(22), []string{"sooner >2023-07-22 14:00"}},
+		{time.Time{}, day(20), []string{"late >2023-07-20"}},
+		{day(26), time.Time{}, nil},
+	}
+	for _, tt := range tests {
+		var got []string
+		for _, task := range c.Due(tt.from, tt.to) {
+			got = append(got, task.Text)
+		}
+		if !equalStrings(got, tt.want) {
+			t.Errorf("Due(%v, %v) = %q, want %q", tt.from, tt.to, got, tt.want)
+		}
+	}
+}
+
+func TestToggle(t *testing.T) {
+	tests := []struct {
+		text string
+		line int
+		task string
+		want string // "" if it fails
+	}{
+		{"[ ] buy milk\n", 1, "buy milk", "[X] buy milk\n"},
+		{"[x] buy milk\n", 1, "buy milk", "[ ] buy milk\n"},
+		{"[X] buy milk", 1, "buy milk", "[ ] buy milk"}, // no newline at the end, and none added
+		{"notes\n  - [ ]  spaced out  \nmore\n", 2, "spaced out", "notes\n  - [X]  spaced out  \nmore\n"},
+		{"a\r\n[ ] crlf\r\nb\r\n", 2, "crlf", "a\r\n[X] crlf\r\nb\r\n"},
+		{"[ ] café ☕\n", 1, "café ☕", "[X] café ☕\n"},
+		// the note has changed since the task was found
+		{"[ ] buy milk\n", 1, "buy bread", ""},
+		{"just text\n", 1, "just text", ""},
+		{"[ ] buy milk\n", 3, "buy milk", ""},
+		{"[ ] buy milk\n", 0, "buy milk", ""},
+	}
+	for _, tt := range tests {
+		got, err := Toggle(tt.text, tt.line, tt.task)
+		if tt.want == "" {
+			if err == nil {
+				t.Errorf("Toggle(%q, %d, %q) = %q, want an error", tt.text, tt.line, tt.task, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Toggle(%q, %d, %q) failed: %s", tt.text, tt.line, tt.task, err)
+		} else if got != tt.want {
+			t.Errorf("Toggle(%q, %d, %q) = %q, want %q", tt.text, tt.line, tt.task, got, tt.want)
+		}
+	}
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
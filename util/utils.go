@@ -2,6 +2,7 @@ package util
 
 import (
 	"sort"
+	"time"
 	"unicode"
 )
 
@@ -111,3 +112,16 @@ func Exclusion[T comparable](a []T, b []T) []T {
 	}
 	return result
 }
+
+// DayOf returns the start of the day of t, in local time, as the dates of notes are; zero stays zero
+func DayOf(t time.Time) time.Time {
+	if t.IsZero() {
+		return t
+	}
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.Local)
+}
+
+// SameDay reports whether a and b fall on the same day
+func SameDay(a, b time.Time) bool {
+	return a.Year() == b.Year() && a.Month() == b.Month() && a.Day() == b.Day()
+}